@@ -1,14 +1,14 @@
 package main
 
 import (
-	"fmt"
 	"os"
 	"time"
 
 	"github.com/teilomillet/gollm"
 )
 
-func NewAI() gollm.LLM {
+// NewAI returns the gollm.LLM of the -provider and -model, or a Failure of kindAI when it cannot be initialized
+func NewAI() (gollm.LLM, error) {
 	provider, model, seed := *figs.String(kAiProvider), *figs.String(kAiModel), *figs.Int(kAiSeed)
 	maxTokens := *figs.Int(kAiMaxTokens)
 	var opts []gollm.ConfigOption
@@ -29,7 +29,9 @@ func NewAI() gollm.LLM {
 	opts = append(opts, gollm.SetTimeout(*figs.UnitDuration(kAiTimeout)))
 	switch provider {
 	case "ollama":
-		capture("unset OLLAMA_API_KEY env", os.Unsetenv("OLLAMA_API_KEY"))
+		if err := os.Unsetenv("OLLAMA_API_KEY"); err != nil {
+			return nil, fail(kindAI, "unset OLLAMA_API_KEY env", err)
+		}
 		opts = append(opts, gollm.SetTemperature(0.99))
 		opts = append(opts, gollm.SetLogLevel(gollm.LogLevelError))
 	default:
@@ -38,8 +40,7 @@ func NewAI() gollm.LLM {
 	}
 	llm, err := gollm.NewLLM(opts...)
 	if err != nil {
-		fmt.Printf("❌ Failed to initialize AI: %v\n", err)
-		return nil
+		return nil, fail(kindAI, "initializing AI", err)
 	}
-	return llm
+	return llm, nil
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+// apply runs `summarize apply [response]`, which reads a model response from a file or STDIN, previews the changes it
+// proposes to the files of kSourceDir as colored diffs, and writes them once they are confirmed
+func apply() error {
+	if err := load(); err != nil {
+		return err
+	}
+	roots := parseRoots(*figs.String(kSourceDir))
+	if len(roots) != 1 {
+		return fail(summarize.KindConfig, "applying changes", errors.New("changes are applied to a single -d at a time"))
+	}
+	workspace := roots[0].Dir
+	args := flag.Args()
+	var response []byte
+	var err error
+	switch {
+	case len(args) > 1:
+		return usage("apply [-d <dir>] [-dry-run] [-yes] [response]")
+	case len(args) == 0 || args[0] == "-":
+		response, err = io.ReadAll(os.Stdin)
+	default:
+		response, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fail(summarize.KindRead, "reading response", err)
+	}
+	patches, err := summarize.Patches(workspace, string(response))
+	if err != nil {
+		return fail(summarize.KindRead, "finding changes in response", err)
+	}
+	if len(patches) == 0 {
+		announce("No file blocks or diffs in the response change the workspace\n")
+		return nil
+	}
+	fmt.Print(preview(patches))
+	if *figs.Bool(kDryRun) {
+		announce(fmt.Sprintf("Dry run: %d changes were not applied\n", len(patches)))
+		return nil
+	}
+	if !*figs.Bool(kYes) && !confirm(fmt.Sprintf("Apply %d changes to %s? [y/N] ", len(patches), workspace)) {
+		announce("No changes were applied\n")
+		return nil
+	}
+	message, err := applyPatches(workspace, patches)
+	if err != nil {
+		return err
+	}
+	announce(message)
+	return nil
+}
+
+// applyPatches writes the patches to the workspace with a backup of every file they overwrite or delete in the
+// kOutputDir, and returns the message describing what was done
+func applyPatches(workspace string, patches []summarize.Patch) (string, error) {
+	backupDir := filepath.Join(*figs.String(kOutputDir), "backups", time.Now().UTC().Format(tFormat))
+	backups, err := summarize.ApplyPatches(workspace, patches, backupDir)
+	if err != nil {
+		return "", fail(summarize.KindRender, "applying changes", err)
+	}
+	if len(backups) == 0 {
+		return fmt.Sprintf("Applied %d changes to %s\n", len(patches), workspace), nil
+	}
+	return fmt.Sprintf("Applied %d changes to %s with backups in %s\n", len(patches), workspace, backupDir), nil
+}
+
+// preview renders the diff of every patch with the added lines in green, the removed lines in red and the hunk
+// headers in cyan
+func preview(patches []summarize.Patch) string {
+	var sb strings.Builder
+	for _, patch := range patches {
+		status := "modified"
+		switch {
+		case !patch.Exists:
+			status = "new file"
+		case patch.Delete:
+			status = "deleted"
+		}
+		sb.WriteString(fileStyle.Render(fmt.Sprintf("%s: %s", status, patch.Path)) + "\n")
+		for _, line := range strings.SplitAfter(patch.Diff, "\n") {
+			text := strings.TrimSuffix(line, "\n")
+			switch {
+			case len(text) == 0:
+				continue
+			case strings.HasPrefix(text, "+++ "), strings.HasPrefix(text, "--- "):
+				text = fileStyle.Render(text)
+			case strings.HasPrefix(text, "+"):
+				text = addedStyle.Render(text)
+			case strings.HasPrefix(text, "-"):
+				text = removedStyle.Render(text)
+			case strings.HasPrefix(text, "@@"):
+				text = hunkStyle.Render(text)
+			}
+			sb.WriteString(text + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// confirm asks the question on the terminal, which still works when the response was piped through STDIN, and
+// reports whether it was answered with yes
+func confirm(question string) bool {
+	fmt.Print(question)
+	var in io.Reader = os.Stdin
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer func() {
+			_ = tty.Close()
+		}()
+		in = tty
+	}
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
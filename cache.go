@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+// cache runs `summarize cache prune`, which removes the entries of the summarize.CacheFilename in the kOutputDir whose
+// files were deleted or changed since they were cached
+func cache() error {
+	if len(os.Args) < 2 || os.Args[1] != "prune" {
+		return usage("cache prune [-o <dir>]")
+	}
+	os.Args = append(os.Args[:1:1], os.Args[2:]...)
+	if err := preprocess(); err != nil {
+		return err
+	}
+	path := filepath.Join(outputDir, summarize.CacheFilename)
+	kept, pruned, err := summarize.PruneCache(path)
+	if err != nil {
+		return fail(summarize.KindRead, "pruning cache", err)
+	}
+	announce(fmt.Sprintf("Cache pruned: %s kept %d and removed %d files\n", path, kept, pruned))
+	return nil
+}
@@ -5,13 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/andreimerlescu/summarize/pkg/summarize"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -27,6 +27,12 @@ var (
 	botStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))            // AI (Cyan)
 	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true) // Error messages
 
+	// Styles for the diffs of the changes previewed by apply
+	fileStyle    = lipgloss.NewStyle().Bold(true)
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // Added lines (Green)
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Removed lines (Red)
+	hunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6")) // Hunk headers (Cyan)
+
 	// A slight border for the chat viewport
 	viewportStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -34,15 +40,19 @@ var (
 		Padding(1)
 )
 
-func StartChat(buf *bytes.Buffer) {
+func StartChat(buf *bytes.Buffer) error {
+	llm, err := NewAI()
+	if err != nil {
+		return err
+	}
 	// Create and run the Bubble Tea program.
 	// tea.WithAltScreen() provides a full-window TUI experience.
 	// CORRECTED: Pass aiPtr.llm directly, not its address.
-	p := tea.NewProgram(initialModel(NewAI(), buf.String()), tea.WithAltScreen(), tea.WithMouseCellMotion())
+	p := tea.NewProgram(initialModel(llm, buf.String()), tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	finalModel, err := p.Run()
 	if err != nil {
-		log.Fatalf("❌ Oh no, there's been an error: %v", err)
+		return fail(kindAI, "running chat", err)
 	}
 
 	if m, ok := finalModel.(model); ok && len(m.messages) > 1 {
@@ -67,6 +77,7 @@ func StartChat(buf *bytes.Buffer) {
 			fmt.Printf("\n📝 Chat log saved to %s\n", filename)
 		}
 	}
+	return nil
 }
 
 // --- BUBBLETEA MESSAGES ---
@@ -91,6 +102,8 @@ type model struct {
 	err          error
 	ctx          context.Context
 	chatHistory  []string
+	lastResponse string
+	pending      []summarize.Patch
 }
 
 // initialModel creates the starting state of our application.
@@ -135,6 +148,7 @@ func initialModel(llm gollm.LLM, summary string) model {
 	}
 
 	msg := fmt.Sprintf("%s %d bytes!", "Welcome to Summarize AI Chat! We've analyzed your project workspace and are ready to chat with you about ", len(summary))
+	msg += " Type /apply to preview the files changed by the last response, then /confirm to write them or /cancel."
 
 	return model{
 		llm:          llm,
@@ -271,6 +285,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.isGenerating || m.textarea.Value() == "" {
 				return m, nil
 			}
+			if input := strings.TrimSpace(m.textarea.Value()); chatCommands[input] {
+				m.messages = append(m.messages, senderStyle.Render("You: ")+m.textarea.Value(), m.command(input))
+				m.textarea.Reset()
+				m.viewport.SetContent(wordwrap.String(strings.Join(m.messages, "\n"), m.viewport.Width))
+				m.viewport.GotoBottom()
+				return m, nil
+			}
 
 			// Add the user's message to the history and set the generating flag.
 			m.messages = append(m.messages, senderStyle.Render("You: ")+m.textarea.Value())
@@ -299,6 +320,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle the AI's response
 	case aiResponseMsg:
 		m.isGenerating = false
+		m.lastResponse = string(msg)
 		m.messages = append(m.messages, botStyle.Render("Summarize AI: ")+string(msg))
 		m.viewport.SetContent(wordwrap.String(strings.Join(m.messages, "\n"), m.viewport.Width))
 		m.viewport.GotoBottom()
@@ -312,6 +334,47 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(taCmd, vpCmd) // Return any commands from the components.
 }
 
+// command runs one of the chatCommands typed into the chat and returns the message that answers it. /apply previews the
+// changes to the workspace in the last response, /confirm writes them with backups and /cancel discards them.
+func (m *model) command(input string) string {
+	switch input {
+	case "/apply":
+		if len(sourceRoots) > 1 {
+			return errorStyle.Render("Error: changes are applied to a single -d at a time")
+		}
+		patches, err := summarize.Patches(sourceDir, m.lastResponse)
+		if err != nil {
+			return errorStyle.Render(fmt.Sprintf("Error: %v", err))
+		}
+		if len(patches) == 0 {
+			return "No file blocks or diffs in the last response change the workspace."
+		}
+		m.pending = patches
+		if *figs.Bool(kDryRun) {
+			return preview(patches) + "Dry run: /confirm will not write these changes."
+		}
+		return preview(patches) + fmt.Sprintf("Type /confirm to write these %d changes or /cancel to discard them.", len(patches))
+	case "/confirm":
+		if len(m.pending) == 0 {
+			return "There are no changes to confirm, type /apply first."
+		}
+		if *figs.Bool(kDryRun) {
+			return "Dry run: no changes were written."
+		}
+		patches := m.pending
+		m.pending = nil
+		message, err := applyPatches(sourceDir, patches)
+		if err != nil {
+			return errorStyle.Render(fmt.Sprintf("Error: %v", err))
+		}
+		return message
+	case "/cancel":
+		m.pending = nil
+		return "The changes were discarded."
+	}
+	return errorStyle.Render(fmt.Sprintf("Unknown command %s, expected /apply, /confirm or /cancel", input))
+}
+
 // View renders the UI. It's called after every Update.
 func (m model) View() string {
 	var bottomLine string
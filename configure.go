@@ -6,6 +6,7 @@ import (
 
 	"github.com/andreimerlescu/figtree/v2"
 	"github.com/andreimerlescu/goenv/env"
+	"github.com/andreimerlescu/summarize/pkg/summarize"
 )
 
 // configure creates a new figtree with options to use CONFIG_FILE as a way of reading a YAML file while ignoring the env
@@ -18,7 +19,7 @@ func configure() {
 	})
 
 	// properties define new fig fruits on the figtree
-	figs = figs.NewString(kSourceDir, ".", "Absolute path of directory you want to summarize.")
+	figs = figs.NewString(kSourceDir, ".", "Absolute path of directory you want to summarize, or a list of them as alias=dir (eg. api=../api,protos=../protos)")
 	figs = figs.NewString(kOutputDir, filepath.Join(".", "summaries"), fmt.Sprintf("Path of the directory to write the %s file to", newSummaryFilename()))
 	figs = figs.NewString(kFilename, newSummaryFilename(), "Output file of summary.md")
 	figs = figs.NewList(kIncludeExt, defaultInclude, "List of extensions to INCLUDE in summary.")
@@ -26,7 +27,44 @@ func configure() {
 	figs = figs.NewList(kSkipContains, defaultAvoid, "List of path substrings if present to skip over full path.")
 	figs = figs.NewInt(kMaxFiles, 369, "Maximum number of files to process concurrently")
 	figs = figs.NewInt64(kMaxOutputSize, 1_776_369, "Maximum file size of output file")
-	figs = figs.NewBool(kDotFiles, false, "Any path that is considered a dotfile can be included by setting this to true")
+	figs = figs.NewInt64(kMaxFileBytes, 0, "Truncate files larger than this many bytes to their -head and -tail lines (0 is unlimited)")
+	figs = figs.NewInt(kMaxFileLines, 0, "Truncate files with more than this many lines to their -head and -tail lines (0 is unlimited)")
+	figs = figs.NewInt(kHeadLines, summarize.DefaultHeadLines, "Lines kept from the start of a truncated file")
+	figs = figs.NewInt(kTailLines, summarize.DefaultTailLines, "Lines kept from the end of a truncated file")
+	figs = figs.NewInt(kMaxTokensOut, 0, "Maximum estimated tokens of output file (0 is unlimited)")
+	figs = figs.NewString(kTokenizer, summarize.TokenizerBPE, "Tokenizer used to estimate tokens (bpe or chars)")
+	figs = figs.NewString(kFormat, summarize.FormatMarkdown, "Format of the summary (markdown, json, yaml, jsonl or xml)")
+	figs = figs.NewString(kTemplate, "", "Path of a Go text/template defining the header, file or footer templates of the summary")
+	figs = figs.NewString(kOrder, summarize.OrderPath, "Order of the files in the summary (path, tree, size, mtime or priority)")
+	figs = figs.NewString(kPriority, "", "Comma separated globs rendered first by -order=priority, in the order given (eg. README*,go.mod,cmd/**)")
+	figs = figs.NewString(kTo, ".", "Directory that summarize unpack recreates the files of a summary in")
+	figs = figs.NewBool(kForce, false, "Let summarize unpack overwrite the files that already exist and write the truncated or redacted ones")
+	figs = figs.NewBool(kStrict, false, "Exit with an error when any file cannot be read or rendered instead of listing it under Errors")
+	figs = figs.NewBool(kDryRun, false, "Preview the changes of summarize apply and /apply without writing them")
+	figs = figs.NewBool(kYes, false, "Write the changes of summarize apply without asking for a confirmation")
+	figs = figs.NewBool(kCompact, false, "Render summarize diff as a markdown summary of what changed to paste into a model")
+	figs = figs.NewBool(kNoCache, false, "Read every file again instead of reusing the cache in the output directory")
+	figs = figs.NewBool(kChunk, false, "Split the summary into numbered parts under -max and -max-tokens-out instead of leaving files out")
+	figs = figs.NewBool(kPack, false, "Rank files by -priority, entry points and demoted tests/fixtures/vendor to fill the budget")
+	figs = figs.NewBool(kDotFiles, false, "No dotfiles: deprecated alias of -dots=exclude that wins over any -dots policy")
+	figs = figs.NewString(kDots, summarize.DotsInclude, "Policy for dotfiles and dot-directories (include, exclude-files, exclude-dirs or exclude)")
+	figs = figs.NewList(kDotAllow, []string{}, "List of dot paths kept by -dots (eg. .github/workflows,.env.example)")
+	figs = figs.NewList(kIncludeGlob, []string{}, "List of globs relative to the source directory of the only files to summarize (eg. cmd/**/*.go)")
+	figs = figs.NewString(kExcludeGlob, "", "Comma separated .gitignore style globs of paths to skip, where the last match wins and ! includes again (eg. *_test.go,!internal/**)")
+	figs = figs.NewList(kExcludeRegex, []string{}, "List of regular expressions of paths relative to the source directory to skip")
+	figs = figs.NewString(kExplain, "", "Print which rule includes or excludes the path relative to the source directory and exit")
+	figs = figs.NewBool(kGitIgnore, true, "Skip paths matched by the .gitignore of every directory")
+	figs = figs.NewBool(kDockerIgnore, true, "Skip paths matched by the .dockerignore in the source directory")
+	figs = figs.NewBool(kSummarizeIgnore, true, "Skip paths matched by the .summarizeignore in the source directory")
+	figs = figs.NewBool(kSkipBinary, true, "Skip files with a NUL byte in their first 8000 bytes")
+	figs = figs.NewBool(kSkipInvalidUTF8, true, "Skip files whose contents are not valid UTF-8")
+	figs = figs.NewInt(kMaxLineLength, 1_000, "Skip files with a line longer than this many bytes, like minified bundles (0 is unlimited)")
+	figs = figs.NewBool(kSkipGenerated, true, "Skip files with a generated code marker like \"Code generated ... DO NOT EDIT.\"")
+	figs = figs.NewBool(kRedact, true, "Replace secrets like AWS keys, GitHub tokens, private keys and JWTs with [REDACTED:kind]")
+	figs = figs.NewBool(kFailOnSecret, false, "Abort without writing a summary when a secret is found")
+	figs = figs.NewString(kGit, "", "Select files from the git repository instead of walking (tracked, changed or staged)")
+	figs = figs.NewString(kGitSince, "HEAD", "Revision that -git=changed compares against (eg. main, v1.2.0, HEAD~3)")
+	figs = figs.NewBool(kGitMergeBase, false, "Compare -git=changed against the merge-base of -since and HEAD")
 	figs = figs.NewBool(kPrint, env.Bool(eAlwaysPrint, false), "Print generated file contents to STDOUT")
 	figs = figs.NewBool(kWrite, env.Bool(eAlwaysWrite, false), "Write generated contents to file")
 	figs = figs.NewBool(kJson, env.Bool(eAlwaysJson, false), "Enable JSON formatting")
@@ -54,6 +92,15 @@ func configure() {
 	figs = figs.WithValidator(kMaxFiles, figtree.AssureIntInRange(1, 369))
 	figs = figs.WithValidator(kMemory, figtree.AssureIntInRange(1, 17_369_369))
 	figs = figs.WithValidator(kMaxOutputSize, figtree.AssureInt64InRange(369, 369_369_369_369))
+	figs = figs.WithValidator(kMaxFileBytes, figtree.AssureInt64InRange(0, 369_369_369_369))
+	figs = figs.WithValidator(kMaxFileLines, figtree.AssureIntInRange(0, 369_369_369_369))
+	figs = figs.WithValidator(kHeadLines, figtree.AssureIntInRange(0, 369_369_369_369))
+	figs = figs.WithValidator(kTailLines, figtree.AssureIntInRange(0, 369_369_369_369))
+	figs = figs.WithValidator(kMaxTokensOut, figtree.AssureIntInRange(0, 369_369_369_369))
+	figs = figs.WithValidator(kTokenizer, figtree.AssureStringNotEmpty)
+	figs = figs.WithValidator(kOrder, figtree.AssureStringNotEmpty)
+	figs = figs.WithValidator(kFormat, figtree.AssureStringNotEmpty)
+	figs = figs.WithValidator(kMaxLineLength, figtree.AssureIntInRange(0, 369_369_369_369))
 	figs = figs.WithValidator(kAiSeed, figtree.AssureIntInRange(-1, 369_369_369_369))
 	figs = figs.WithValidator(kAiMaxTokens, figtree.AssureIntInRange(-1, 369_369_369_369))
 
@@ -64,7 +64,8 @@ const (
 
 	kChat string = "chat"
 
-	// kSourceDir figtree fig string -d for the directory path to generate a summary of
+	// kSourceDir figtree fig string -d for the directory path to generate a summary of, or a comma separated list of
+	// directories to summarize together that are written as alias=dir to give them an alias
 	kSourceDir string = "d"
 
 	// kOutputDir figtree fig string -o for the output directory where the summary is saved
@@ -88,15 +89,51 @@ const (
 	// kMaxOutputSize figtree fig int64 -max will stop summarizing the kSourceDir once kFilename reaches this size in bytes
 	kMaxOutputSize string = "max"
 
+	// kMaxTokensOut figtree fig int -max-tokens-out will stop adding files once the summary would exceed this many tokens
+	kMaxTokensOut string = "max-tokens-out"
+
+	// kTokenizer figtree fig string -tokenizer is the name of the tokenizer used to estimate tokens (bpe or chars)
+	kTokenizer string = "tokenizer"
+
+	// kOrder figtree fig string -order is the order the files are rendered in (path, tree, size, mtime or priority)
+	kOrder string = "order"
+
+	// kPriority figtree fig string -priority are the comma separated globs rendered first, in the order of the globs, by
+	// -order=priority, which is a string since a figtree list is sorted
+	kPriority string = "priority"
+
+	// kPack figtree fig bool -pack will rank every file and fill -max and -max-tokens-out from the top of the ranking
+	kPack string = "pack"
+
 	// kWrite figtree fig bool -write will write the summary to the kFilename in the kSourceDir
 	kWrite string = "write"
 
 	// kVersion figtree fig bool -v will display the current version of the binary
 	kVersion string = "v"
 
-	// kDotFiles figtree fig bool -ndf will skip over any directory that has a prefix of "."
+	// kDotFiles figtree fig bool -ndf (no dotfiles) is the deprecated alias of kDots=exclude, which wins over any kDots
 	kDotFiles string = "ndf"
 
+	// kDots figtree fig string -dots is the policy for dotfiles and dot-directories (include, exclude-files,
+	// exclude-dirs or exclude)
+	kDots string = "dots"
+
+	// kDotAllow figtree fig list -dot-allow are the paths relative to kSourceDir that kDots keeps, like .github/workflows
+	kDotAllow string = "dot-allow"
+
+	// kIncludeGlob figtree fig list -include-glob are the globs relative to kSourceDir of the only files to summarize
+	kIncludeGlob string = "include-glob"
+
+	// kExcludeGlob figtree fig string -exclude-glob are the comma separated .gitignore style globs relative to kSourceDir
+	// of the paths to skip, which is a string since the last glob that matches wins and a figtree list is sorted
+	kExcludeGlob string = "exclude-glob"
+
+	// kExcludeRegex figtree fig list -exclude-regex are the regular expressions of the paths relative to kSourceDir to skip
+	kExcludeRegex string = "exclude-regex"
+
+	// kExplain figtree fig string -explain prints which rule includes or excludes the path instead of summarizing
+	kExplain string = "explain"
+
 	// kMaxFiles figtree fig int64 -mf will specify the maximum number of files that will concurrently be summarized
 	kMaxFiles string = "mf"
 
@@ -106,6 +143,105 @@ const (
 	// kJson figtree fig bool -json will render the output as JSON to the console's STDOUT only
 	kJson string = "json"
 
+	// kGitIgnore figtree fig bool -gitignore will skip paths matched by the .gitignore of every directory in kSourceDir
+	kGitIgnore string = "gitignore"
+
+	// kDockerIgnore figtree fig bool -dockerignore will skip paths matched by the .dockerignore in kSourceDir
+	kDockerIgnore string = "dockerignore"
+
+	// kSummarizeIgnore figtree fig bool -summarizeignore will skip paths matched by the .summarizeignore in kSourceDir
+	kSummarizeIgnore string = "summarizeignore"
+
+	// kGit figtree fig string -git will select the tracked, changed or staged files of the git repository of kSourceDir
+	kGit string = "git"
+
+	// kGitSince figtree fig string -since is the revision that -git=changed compares the worktree against
+	kGitSince string = "since"
+
+	// kGitMergeBase figtree fig bool -merge-base compares -git=changed against the merge-base of -since and HEAD
+	kGitMergeBase string = "merge-base"
+
+	// kSkipBinary figtree fig bool -skip-binary will skip files with a NUL byte in their first 8000 bytes
+	kSkipBinary string = "skip-binary"
+
+	// kSkipInvalidUTF8 figtree fig bool -skip-invalid-utf8 will skip files whose contents are not valid UTF-8
+	kSkipInvalidUTF8 string = "skip-invalid-utf8"
+
+	// kMaxLineLength figtree fig int -max-line-length will skip files with a longer line, such as minified bundles
+	kMaxLineLength string = "max-line-length"
+
+	// kSkipGenerated figtree fig bool -skip-generated will skip files carrying a "Code generated ... DO NOT EDIT." marker
+	kSkipGenerated string = "skip-generated"
+
+	// kRedact figtree fig bool -redact will replace secrets found in the files with a [REDACTED:kind] placeholder
+	kRedact string = "redact"
+
+	// kFailOnSecret figtree fig bool -fail-on-secret will abort instead of writing a summary when a secret is found
+	kFailOnSecret string = "fail-on-secret"
+
+	// kMaxFileBytes figtree fig int64 -max-file-bytes will truncate files larger than this to their -head and -tail lines
+	kMaxFileBytes string = "max-file-bytes"
+
+	// kMaxFileLines figtree fig int -max-file-lines will truncate files longer than this to their -head and -tail lines
+	kMaxFileLines string = "max-file-lines"
+
+	// kHeadLines figtree fig int -head is how many lines from the start of a truncated file are kept
+	kHeadLines string = "head"
+
+	// kTailLines figtree fig int -tail is how many lines from the end of a truncated file are kept
+	kTailLines string = "tail"
+
+	// kFormat figtree fig string -format renders the summary as markdown, json, yaml, jsonl or xml
+	kFormat string = "format"
+
+	// kTemplate figtree fig string -template is the path of a text/template that renders the markdown summary
+	kTemplate string = "template"
+
+	// kTo figtree fig string -to is the directory that `summarize unpack` recreates the files of a summary in
+	kTo string = "to"
+
+	// kForce figtree fig bool -force lets `summarize unpack` overwrite the files that already exist in kTo and write the
+	// files that were truncated or redacted as they were summarized
+	kForce string = "force"
+
+	// kDryRun figtree fig bool -dry-run lets `summarize apply` and the /apply chat command preview changes without writing them
+	kDryRun string = "dry-run"
+
+	// kYes figtree fig bool -yes lets `summarize apply` write the changes without asking for a confirmation
+	kYes string = "yes"
+
+	// kCompact figtree fig bool -compact renders `summarize diff` as a markdown summary of what changed for a model
+	kCompact string = "compact"
+
+	// kNoCache figtree fig bool -no-cache will read every file again instead of reusing the cache in kOutputDir
+	kNoCache string = "no-cache"
+
+	// kChunk figtree fig bool -chunk will split the summary into parts under -max and -max-tokens-out with a manifest
+	kChunk string = "chunk"
+
+	// kStrict figtree fig bool -strict will exit with the code of the first error when any file could not be summarized
+	kStrict string = "strict"
+
 	// kCompress figtree fig bool -gz will gzip compress the contents of kFilename that is written to kOutputDir
 	kCompress string = "gz"
 )
+
+const (
+	// kindAI is the Failure.Kind of an AI provider that could not be reached or a chat that failed
+	kindAI string = "ai"
+
+	// kindSecret is the kind of a summary stopped by -fail-on-secret because it found a secret
+	kindSecret string = "secret"
+)
+
+// The exit codes of the summarize program by the kind of error that ended it
+const (
+	exitOK     int = 0
+	exitError  int = 1 // an error of no known kind
+	exitConfig int = 2 // invalid flags, config or usage
+	exitWalk   int = 3 // the -d could not be walked
+	exitRead   int = 4 // a file or summary could not be read
+	exitRender int = 5 // a summary could not be rendered or written
+	exitAI     int = 6 // the AI provider failed
+	exitSecret int = 7 // -fail-on-secret found a secret
+)
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+// diff runs `summarize diff <old> <new>`, which prints the files that were added, removed or modified between two
+// summaries with their unified diffs, as a markdown summary of what changed with -compact or as JSON with -json
+func diff() error {
+	if err := load(); err != nil {
+		return err
+	}
+	args := flag.Args()
+	if len(args) != 2 {
+		return usage("diff [-compact] [-json] <old> <new>")
+	}
+	older, err := readSummary(args[0])
+	if err != nil {
+		return err
+	}
+	newer, err := readSummary(args[1])
+	if err != nil {
+		return err
+	}
+	changes := summarize.Compare(older, newer)
+	switch {
+	case *figs.Bool(kJson):
+		jb, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return fail(summarize.KindRender, "marshalling changes", err)
+		}
+		fmt.Println(string(jb))
+	case *figs.Bool(kCompact):
+		fmt.Print(compact(args[0], args[1], newer.Workspace, changes))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, c := range changes {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t+%d -%d\n", c.Status, c.Path, c.Added, c.Removed)
+		}
+		if err := w.Flush(); err != nil {
+			return fail(summarize.KindRender, "writing changes", err)
+		}
+		for _, c := range changes {
+			fmt.Print("\n" + c.Diff)
+		}
+	}
+	return nil
+}
+
+// readSummary parses the summary at path, decompressing it first when it was written with -gz
+func readSummary(path string) (*summarize.Document, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fail(summarize.KindRead, "reading summary", err)
+	}
+	if bytes.HasPrefix(contents, []byte{0x1f, 0x8b}) {
+		decompressed, err := decompress(contents)
+		if err != nil {
+			return nil, fail(summarize.KindRead, "decompressing summary", err)
+		}
+		contents = []byte(decompressed)
+	}
+	doc, err := summarize.Parse(contents)
+	if err != nil {
+		return nil, fail(summarize.KindRead, "parsing summary "+path, err)
+	}
+	return doc, nil
+}
+
+// compact renders the changes from the older to the newer summary as markdown that lists every change and shows the
+// diff of the added and modified files, leaving out the contents of the removed files
+func compact(older, newer, workspace string, changes []summarize.Change) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Changes - %s to %s\n\n", filepath.Base(older), filepath.Base(newer)))
+	if len(workspace) > 0 {
+		sb.WriteString(fmt.Sprintf("### Workspace\n\n`%s`\n\n", workspace))
+	}
+	if len(changes) == 0 {
+		sb.WriteString("No files changed.\n")
+		return sb.String()
+	}
+	sb.WriteString("| Status | Path | Added | Removed |\n")
+	sb.WriteString("|--------|------|-------|---------|\n")
+	for _, c := range changes {
+		sb.WriteString(fmt.Sprintf("| %s | `%s` | %d | %d |\n", c.Status, c.Path, c.Added, c.Removed))
+	}
+	for _, c := range changes {
+		if c.Status == summarize.ChangeRemoved {
+			continue
+		}
+		ticks := fence(c.Diff)
+		sb.WriteString(fmt.Sprintf("\n## %s (%s)\n\n%sdiff\n%s%s\n", c.Path, c.Status, ticks, c.Diff, ticks))
+	}
+	return sb.String()
+}
+
+// fence returns a code fence that is longer than any run of backticks in the contents
+func fence(contents string) string {
+	longest, run := 0, 0
+	for _, c := range contents {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+// Error returns the Message of the Failure followed by the error that caused it
+func (f *Failure) Error() string {
+	if f.Err == nil {
+		return f.Message
+	}
+	return fmt.Sprintf("%s: %v", f.Message, f.Err)
+}
+
+// Unwrap returns the error that caused the Failure
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+// explain prints which rule of the options includes or excludes the path, as JSON when -json is enabled
+func explain(summarizer *summarize.Summarizer, path string) error {
+	e, err := summarizer.Explain(path)
+	if err != nil {
+		return fail(kindOf(err), "explaining "+path, err)
+	}
+	if *figs.Bool(kJson) {
+		jb, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return fail(summarize.KindRender, "marshalling explanation", err)
+		}
+		fmt.Println(string(jb))
+		return nil
+	}
+	fmt.Println(describe(e))
+	return nil
+}
+
+// describe returns a line such as "excluded: pkg/a_test.go by -exclude-glob *_test.go" for the Explanation
+func describe(e summarize.Explanation) string {
+	verdict := "excluded"
+	if e.Included {
+		verdict = "included"
+	}
+	subject := e.Path
+	if len(subject) == 0 {
+		subject = "."
+	}
+	if len(e.Type) > 0 {
+		subject += fmt.Sprintf(" (%s)", e.Type)
+	}
+	if len(e.Rule) == 0 {
+		return fmt.Sprintf("%s: %s is a directory that is walked", verdict, subject)
+	}
+	return fmt.Sprintf("%s: %s by %s", verdict, subject, because(e))
+}
+
+// because returns the flag and the match of the rule that decided the Explanation, such as "-s node_modules/"
+func because(e summarize.Explanation) string {
+	if e.Rule == summarize.RuleContent {
+		if flag, ok := contentFlags[e.Match]; ok {
+			return flag
+		}
+		return e.Match
+	}
+	rule, ok := ruleFlags[e.Rule]
+	if !ok {
+		rule = e.Rule
+	}
+	reason := rule + " " + e.Match
+	if len(e.Match) == 0 {
+		reason = rule + ", which does not list it"
+	}
+	if len(e.Dir) > 0 {
+		reason += fmt.Sprintf(" on the directory %s/", e.Dir)
+	}
+	return reason
+}
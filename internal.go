@@ -4,212 +4,48 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
-	"sync"
-	"sync/atomic"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
 )
 
-// analyze is called from investigate where the path is inspected and the resultsChan is written to
-func analyze(ext, filePath string) {
-	defer maxFileSemaphore.Release() // maxFileSemaphore prevents excessive files from being opened
-	defer wg.Done()                  // keep the main thread running while this file is being processed
-	if strings.HasSuffix(filePath, ".DS_Store") ||
-		strings.HasSuffix(filePath, ".exe") ||
-		strings.HasSuffix(filePath, "-amd64") ||
-		strings.HasSuffix(filePath, "-arm64") ||
-		strings.HasSuffix(filePath, "aarch64") {
-		return
-	}
-	type tFileInfo struct {
-		Name string      `json:"name"`
-		Size int64       `json:"size"`
-		Mode os.FileMode `json:"mode"`
-	}
-	info, err := os.Stat(filePath)
-	if err != nil {
-		errs = append(errs, err)
-		return
-	}
-	fileInfo := &tFileInfo{
-		Name: filepath.Base(filePath),
-		Size: info.Size(),
-		Mode: info.Mode(),
-	}
-	infoJson, err := json.MarshalIndent(fileInfo, "", "  ")
-	if err != nil {
-		errs = append(errs, err)
+// done is responsible for printing the path of the generated summary to STDOUT when the summarize program is finished
+func done(generated string) {
+	announce(fmt.Sprintf("Summary generated: %s\n", generated))
+}
+
+// announce prints the message to STDOUT, as an M when -json is enabled
+func announce(message string) {
+	if !*figs.Bool(kJson) {
+		fmt.Print(message)
 		return
 	}
-	var sb bytes.Buffer // capture what we write to file in a bytes buffer
-	sb.WriteString("## " + filepath.Base(filePath) + "\n\n")
-	sb.WriteString("The `os.Stat` for the " + filePath + " is: \n\n")
-	sb.WriteString("```json\n")
-	sb.WriteString(string(infoJson) + "\n")
-	sb.WriteString("```\n\n")
-	sb.WriteString("Source Code:\n\n")
-	sb.WriteString("```" + ext + "\n")
-	content, err := os.ReadFile(filePath) // open the file and get its contents
+	jb, err := json.MarshalIndent(M{Message: message}, "", "  ")
 	if err != nil {
-		errs = append(errs, fmt.Errorf("Error reading file %s: %v\n", filePath, err))
-		return
-	}
-	if _, writeErr := sb.Write(content); writeErr != nil {
-		errs = append(errs, fmt.Errorf("Error writing file %s: %v\n", filePath, err))
+		fmt.Print(message)
 		return
 	}
-	content = []byte{}          // clear memory after its written
-	sb.WriteString("\n```\n\n") // close out the file footer
-	seen.Add(filePath)
-	resultsChan <- Result{
-		Path:     filePath,
-		Contents: sb.Bytes(),
-		Size:     int64(sb.Len()),
-	}
-}
-
-// done is responsible for printing the results to STDOUT when the summarize program is finished
-func done() {
-	// Print completion message
-	if *figs.Bool(kJson) {
-		r := M{
-			Message: fmt.Sprintf("Summary generated: %s\n",
-				filepath.Join(*figs.String(kOutputDir), *figs.String(kFilename)),
-			),
-		}
-		jb, err := json.MarshalIndent(r, "", "  ")
-		if err != nil {
-			terminate(os.Stderr, "Error marshalling results: %v\n", err)
-		} else {
-			fmt.Println(string(jb))
-		}
-	} else {
-		fmt.Printf("Summary generated: %s\n",
-			filepath.Join(*figs.String(kOutputDir), *figs.String(kFilename)),
-		)
-	}
-}
-
-// iterate is a func that gets passed directly into the data.Range(iterate) that runs investigate concurrently with the
-// wg and throttler enabled
-func iterate(e, p any) bool {
-	ext, ok := e.(string)
-	if !ok {
-		return true // continue
-	}
-	thisData, ok := p.(mapData)
-	if !ok {
-		return true // continue
-	}
-	paths := slices.Clone(thisData.Paths)
-
-	throttler.Acquire() // throttler is used to protect the runtime from excessive use
-	wg.Add(1)           // wg is used to prevent the runtime from exiting early
-	go investigate(&thisData, &toUpdate, ext, paths)
-	return true
-}
-
-// investigate is called from iterate where it takes an extension and a slice of paths to analyze each path
-func investigate(innerData *mapData, toUpdate *[]mapData, ext string, paths []string) {
-	defer throttler.Release() // when we're done, release the throttler
-	defer wg.Done()           // then tell the sync.WaitGroup that we are done
-
-	paths = simplify(paths)
-
-	innerData.Paths = paths
-	*toUpdate = append(*toUpdate, *innerData)
-
-	// process each file in the ext list (one ext per throttle slot in the semaphore)
-	for _, filePath := range paths {
-		if seen.Exists(filePath) {
-			continue
-		}
-		maxFileSemaphore.Acquire()
-		wg.Add(1)
-		go analyze(ext, filePath)
-	}
+	fmt.Println(string(jb))
 }
 
-// populate is responsible for loading new paths into the *sync.Map called data
-func populate(ext, path string) {
-	todo := make([]mapData, 0)
-	if data == nil {
-		panic("data is nil")
-	}
-	// populate the -inc list in data
-	data.Range(func(e any, p any) bool {
-		key, ok := e.(string)
-		if !ok {
-			return true // continue
-		}
-		value, ok := p.(mapData)
-		if !ok {
-			return true
-		}
-		if strings.EqualFold(key, ext) {
-			value.Ext = key
-		}
-		value.Paths = append(value.Paths, path)
-		todo = append(todo, value)
-		return true
-	})
-	for _, value := range todo {
-		data.Store(value.Ext, value)
-	}
-}
-
-// receive will accept Result from resultsChan and write to the summary file. If `-chat` is enabled, the StartChat will
-// get called. Once the chat session is completed, the contents of the chat log is injected into the summary file.
-func receive() {
-	if writerWG == nil {
-		panic("writer wg is nil")
-	}
-	defer writerWG.Done()
-
+// receive will accept the summarize.Summary and write it to the summary file, returning the path that was generated.
+// If `-chat` is enabled, the StartChat will get called. Once the chat session is completed, the contents of the chat
+// log is injected into the summary file.
+func receive(summary *summarize.Summary) (string, error) {
 	// Create output file
-	srcDir := *figs.String(kSourceDir)
 	outputFileName := filepath.Join(*figs.String(kOutputDir), *figs.String(kFilename))
-	var buf bytes.Buffer
-	buf.WriteString("# Project Summary - " + filepath.Base(*figs.String(kFilename)) + "\n")
-	buf.WriteString("Generated by " + projectName + " " + Version() + "\n\n")
-	buf.WriteString("AI Instructions are the user requests that you analyze their project workspace ")
-	buf.WriteString("as provided here by filename followed by the contents. You are to answer their ")
-	buf.WriteString("question using the source code provided as the basis of your responses. You are to ")
-	buf.WriteString("completely modify each individual file as per-the request and provide the completely ")
-	buf.WriteString("updated form of the file. Do not abbreviate the file, and if the file is excessive in ")
-	buf.WriteString("length, then print the entire contents in your response with your updates to the ")
-	buf.WriteString("specific components while retaining all existing functionality and maintaining comments ")
-	buf.WriteString("within the code.  \n\n")
-	buf.WriteString("### Workspace\n\n")
-	abs, err := filepath.Abs(srcDir)
-	if err == nil {
-		buf.WriteString("`" + abs + "`\n\n")
-	} else {
-		buf.WriteString("`" + srcDir + "`\n\n")
+	if len(summary.Chunks) > 0 {
+		return renderChunks(outputFileName, summary)
 	}
+	var buf bytes.Buffer
+	buf.Write(summary.Contents)
 
-	renderMu := &sync.Mutex{}
-	renderedPaths := make(map[string]int64)
-	totalSize := int64(buf.Len())
-	for in := range resultsChan {
-		if _, exists := renderedPaths[in.Path]; exists {
-			continue
+	if *figs.Bool(kChat) && *figs.String(kFormat) == summarize.FormatMarkdown {
+		if err := StartChat(&buf); err != nil {
+			return "", err
 		}
-		runningSize := atomic.AddInt64(&totalSize, in.Size)
-		if runningSize >= *figs.Int64(kMaxOutputSize) {
-			continue
-		}
-		renderMu.Lock()
-		renderedPaths[in.Path] = in.Size
-		buf.Write(in.Contents)
-		renderMu.Unlock()
-	}
-
-	if *figs.Bool(kChat) {
-		StartChat(&buf)
 		path := latestChatLog()
 		contents, err := os.ReadFile(path)
 		if err == nil {
@@ -224,11 +60,12 @@ func receive() {
 			buf.WriteString(old)
 		}
 	}
-	render(&buf, outputFileName)
+	return render(&buf, outputFileName, summary)
 }
 
-// render will take the summary and either write it to a file, STDOUT or present an error to STDERR
-func render(buf *bytes.Buffer, outputFileName string) {
+// render will take the summary and either write it to a file or STDOUT, returning the path that was generated or an
+// empty string when it was printed
+func render(buf *bytes.Buffer, outputFileName string, summary *summarize.Summary) (string, error) {
 	shouldPrint := *figs.Bool(kPrint)
 	canWrite := *figs.Bool(kWrite)
 	showJson := *figs.Bool(kJson)
@@ -236,112 +73,119 @@ func render(buf *bytes.Buffer, outputFileName string) {
 
 	if *figs.Bool(kCompress) {
 		compressed, err := compress(bytes.Clone(buf.Bytes()))
-		capture("compressing bytes buffer", err)
+		if err != nil {
+			return "", fail(summarize.KindRender, "compressing bytes buffer", err)
+		}
 		buf.Reset()
 		buf.Write(compressed)
 		outputFileName += ".gz"
 	}
 
 	if !shouldPrint && !canWrite {
-		capture("saving output file during write", os.WriteFile(outputFileName, buf.Bytes(), 0644))
+		if err := os.WriteFile(outputFileName, buf.Bytes(), 0644); err != nil {
+			return "", fail(summarize.KindRender, "saving output file during write", err)
+		}
 		wrote = true
 	}
 
 	if canWrite && !wrote {
-		capture("saving output file during write", os.WriteFile(outputFileName, buf.Bytes(), 0644))
+		if err := os.WriteFile(outputFileName, buf.Bytes(), 0644); err != nil {
+			return "", fail(summarize.KindRender, "saving output file during write", err)
+		}
 		wrote = true
 	}
 
 	if shouldPrint {
 		if showJson {
 			r := Final{
-				Path:     outputFileName,
-				Size:     int64(buf.Len()),
-				Contents: buf.String(),
+				Path:       outputFileName,
+				Size:       int64(buf.Len()),
+				Contents:   buf.String(),
+				Tokens:     summary.Tokens,
+				Files:      make([]summarize.File, 0, len(summary.Results)),
+				Omitted:    summary.Omitted,
+				Redactions: summary.Redactions,
+				Truncated:  summary.Truncated,
+				Errors:     summary.Errors,
+			}
+			for _, result := range summary.Results {
+				r.Files = append(r.Files, result.File)
 			}
 			jb, err := json.MarshalIndent(r, "", "  ")
 			if err != nil {
-				_, _ = fmt.Fprintln(os.Stderr, err)
+				return "", fail(summarize.KindRender, "marshalling summary", err)
 			}
 			fmt.Println(string(jb))
-		} else {
+		} else if *figs.String(kFormat) == summarize.FormatMarkdown {
 			fmt.Println(buf.String())
+		} else {
+			fmt.Print(buf.String())
 		}
-		os.Exit(0)
-
+		return "", nil
 	}
+	return outputFileName, nil
 }
 
-// summarize walks through a filepath recursively and matches paths that get stored inside the
-// data *sync.Map for the extension.
-func summarize(path string, info fs.FileInfo, err error) error {
-	if err != nil {
-		return err // return the error received
-	}
-	if !info.IsDir() {
-
-		// get the filename
-		filename := filepath.Base(path)
-
-		if *figs.Bool(kDotFiles) {
-			if strings.HasPrefix(filename, ".") {
-				return nil // skip without error
+// renderChunks writes every summarize.Chunk of the summary next to a Manifest of the files in each of them, returning
+// the path of the Manifest. With -print the Manifest is written to STDOUT instead of a file and the path is empty.
+func renderChunks(outputFileName string, summary *summarize.Summary) (string, error) {
+	compressed := *figs.Bool(kCompress)
+	manifest := newManifest(outputFileName, summary, compressed)
+	for i, chunk := range summary.Chunks {
+		contents := chunk.Contents
+		if compressed {
+			var err error
+			if contents, err = compress(bytes.Clone(contents)); err != nil {
+				return "", fail(summarize.KindRender, "compressing bytes buffer", err)
 			}
 		}
-
-		// check the -avoid list
-		for _, avoidThis := range lSkipContains {
-			a := strings.Contains(filename, avoidThis) || strings.Contains(path, avoidThis)
-			b := strings.HasPrefix(filename, avoidThis) || strings.HasPrefix(path, avoidThis)
-			c := strings.HasSuffix(filename, avoidThis) || strings.HasSuffix(path, avoidThis)
-			if a || b || c {
-				if isDebug {
-					fmt.Printf("ignoring %s in %s\n", filename, path)
-				}
-				return nil // skip without error
-			}
-
-			parts, err := filepath.Glob(path)
-			if err != nil {
-				errs = append(errs, err)
-				continue
-			}
-			for i := 0; i < len(parts); i++ {
-				part := parts[i]
-				if strings.EqualFold(part, string(os.PathSeparator)) {
-					continue
-				}
-				if strings.Contains(part, avoidThis) || strings.HasPrefix(part, avoidThis) || strings.HasSuffix(part, avoidThis) {
-					if isDebug {
-						fmt.Printf("skipping file %q\n", part)
-					}
-					return nil
-				}
-			}
-
-		}
-
-		// get the extension
-		ext := filepath.Ext(path)
-		ext = strings.ToLower(ext)
-		ext = strings.TrimPrefix(ext, ".")
-
-		if isDebug {
-			fmt.Printf("ext: %s\n", ext)
+		if err := os.WriteFile(manifest.Chunks[i].Path, contents, 0644); err != nil {
+			return "", fail(summarize.KindRender, "saving output file during write", err)
 		}
-
-		// check the -exc list
-		for _, excludeThis := range lExcludeExt {
-			if strings.EqualFold(excludeThis, ext) {
-				if isDebug {
-					fmt.Printf("ignoring %s\n", path)
-				}
-				return nil // skip without error
-			}
-		}
-		populate(ext, path)
 	}
+	jb, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fail(summarize.KindRender, "marshalling manifest", err)
+	}
+	if *figs.Bool(kPrint) {
+		fmt.Println(string(jb))
+		return "", nil
+	}
+	manifestFileName := strings.TrimSuffix(outputFileName, filepath.Ext(outputFileName)) + ".manifest.json"
+	if err := os.WriteFile(manifestFileName, jb, 0644); err != nil {
+		return "", fail(summarize.KindRender, "saving manifest during write", err)
+	}
+	return manifestFileName, nil
+}
 
-	// continue to the next file
-	return nil
+// newManifest lists every summarize.Chunk of the summary under the numbered path next to the outputFileName that it is
+// written to, which ends in .gz when the chunks are compressed
+func newManifest(outputFileName string, summary *summarize.Summary, compressed bool) Manifest {
+	ext := filepath.Ext(outputFileName)
+	base := strings.TrimSuffix(outputFileName, ext)
+	manifest := Manifest{
+		Workspace:  summary.Workspace,
+		Parts:      len(summary.Chunks),
+		Size:       summary.Size,
+		Tokens:     summary.Tokens,
+		Chunks:     make([]ManifestChunk, 0, len(summary.Chunks)),
+		Omitted:    summary.Omitted,
+		Redactions: summary.Redactions,
+		Truncated:  summary.Truncated,
+		Errors:     summary.Errors,
+	}
+	for _, chunk := range summary.Chunks {
+		path := fmt.Sprintf("%s.part-%03d-of-%03d%s", base, chunk.Part, len(summary.Chunks), ext)
+		if compressed {
+			path += ".gz"
+		}
+		manifest.Chunks = append(manifest.Chunks, ManifestChunk{
+			Path:   path,
+			Part:   chunk.Part,
+			Size:   chunk.Size,
+			Tokens: chunk.Tokens,
+			Files:  chunk.Files,
+		})
+	}
+	return manifest
 }
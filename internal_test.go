@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+func TestNewManifest(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 6; i++ {
+		contents := fmt.Sprintf("package p%d\n\n%s", i, strings.Repeat("// filler line of the package\n", 20))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("p%d.go", i)), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := summarize.New(summarize.Options{
+		SourceDir:     dir,
+		SkipContains:  []string{},
+		IncludeExt:    []string{"go"},
+		Chunk:         true,
+		MaxOutputSize: 2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Chunks) < 2 {
+		t.Fatalf("Run split the summary into %d chunks, want several", len(summary.Chunks))
+	}
+	for _, compressed := range []bool{false, true} {
+		manifest := newManifest(filepath.Join("out", "summary.md"), summary, compressed)
+		if manifest.Parts != len(summary.Chunks) || len(manifest.Chunks) != manifest.Parts {
+			t.Fatalf("the manifest lists %d of %d parts, want %d", len(manifest.Chunks), manifest.Parts, len(summary.Chunks))
+		}
+		var size int64
+		for i, chunk := range manifest.Chunks {
+			size += chunk.Size
+			want := filepath.Join("out", fmt.Sprintf("summary.part-%03d-of-%03d.md", i+1, manifest.Parts))
+			if compressed {
+				want += ".gz"
+			}
+			if chunk.Path != want || chunk.Part != i+1 {
+				t.Errorf("part %d is written to %s as part %d, want %s", i+1, chunk.Path, chunk.Part, want)
+			}
+		}
+		if manifest.Size == 0 || manifest.Size != size {
+			t.Errorf("the manifest has a size of %d, want the %d bytes of its chunks", manifest.Size, size)
+		}
+	}
+}
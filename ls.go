@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+// ls runs `summarize ls`, which walks the kSourceDir with the same options as a summary and prints every path it
+// reached as included or excluded by the rule responsible, with the size and estimated tokens of the files and the
+// totals, without writing a summary
+func ls() error {
+	if err := preprocess(); err != nil {
+		return err
+	}
+	opts, err := options()
+	if err != nil {
+		return err
+	}
+	summarizer, err := summarize.New(opts)
+	if err != nil {
+		return fail(summarize.KindConfig, "configuring summarizer", err)
+	}
+	listings, err := summarizer.List(context.Background())
+	if listings == nil && err != nil {
+		return fail(kindOf(err), "listing source directory", err)
+	}
+	listed := Listed{Paths: listings}
+	for _, l := range listings {
+		if !l.Included {
+			listed.Totals.Excluded++
+			continue
+		}
+		listed.Totals.Included++
+		listed.Totals.Size += l.Size
+		listed.Totals.Tokens += l.Tokens
+	}
+	if *figs.Bool(kJson) {
+		jb, jsonErr := json.MarshalIndent(listed, "", "  ")
+		if jsonErr != nil {
+			return fail(summarize.KindRender, "marshalling listing", jsonErr)
+		}
+		fmt.Println(string(jb))
+	} else if writeErr := table(listed); writeErr != nil {
+		return fail(summarize.KindRender, "writing listing", writeErr)
+	}
+	if err != nil && !*figs.Bool(kStrict) {
+		_, _ = fmt.Fprintln(os.Stderr, "Some files could not be read, they are listed as excluded by a read error")
+		return nil
+	}
+	return fail(kindOf(err), "listing source directory", err)
+}
+
+// table prints the Paths of the listed as a table of their status, size, estimated tokens and rule, followed by the
+// Totals
+func table(listed Listed) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STATUS\tSIZE\tTOKENS\tPATH\tRULE")
+	for _, l := range listed.Paths {
+		status, size, tokens, path := "excluded", strconv.FormatInt(l.Size, 10), "-", l.Path
+		if l.Included {
+			status, tokens = "included", strconv.Itoa(l.Tokens)
+		}
+		if l.IsDir {
+			size, path = "-", path+"/"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status, size, tokens, path, because(l.Explanation))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	t := listed.Totals
+	fmt.Printf("\nIncluded %d files of %d bytes and about %d tokens, excluded %d paths\n", t.Included, t.Size, t.Tokens, t.Excluded)
+	return nil
+}
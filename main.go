@@ -1,5 +1,18 @@
 package main
 
+import "os"
+
 func main() {
-	process()
+	os.Exit(report(run()))
+}
+
+// run summarizes the kSourceDir, or runs the command named by the first argument, and returns the error that ended it
+func run() error {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Args = append(os.Args[:1:1], os.Args[2:]...)
+			return command()
+		}
+	}
+	return process()
 }
@@ -0,0 +1,476 @@
+package summarize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// hunkHeader matches the "@@ -start,count +start,count @@" line that starts every hunk of a unified diff
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// pathLabel matches the labels that a response puts in front of the path of a file block, such as "File: main.go"
+var pathLabel = regexp.MustCompile(`(?i)^(?:file(?:name)?|path)\s*:\s*`)
+
+// Patches finds the changes that a model response proposes to the files of the workspace, as fenced code blocks that
+// hold the full contents of a file or as unified diffs, and returns a Patch for each file that would change. A block
+// names its file in the info string of its fence, on the line before it, or like the DefaultTemplate does. Blocks
+// and diffs naming a path outside of the workspace are ignored.
+func Patches(workspace, response string) ([]Patch, error) {
+	root, err := os.OpenRoot(workspace)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	p := &patcher{workspace: workspace, root: root, index: make(map[string]int)}
+	lines := strings.Split(strings.ReplaceAll(response, "\r\n", "\n"), "\n")
+	var stated, previous string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			end := diffEnd(lines, i+2)
+			if err := p.diff(lines[i:end]); err != nil {
+				return nil, err
+			}
+			i, previous = end-1, ""
+			continue
+		case len(markdownPath(line)) > 0:
+			stated = markdownPath(line)
+		}
+		ticks, info, ok := openingFence(line)
+		if !ok {
+			if len(strings.TrimSpace(line)) > 0 {
+				previous = strings.TrimSpace(line)
+			}
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !closingFence(lines[end], ticks) {
+			end++
+		}
+		body := lines[i+1 : min(end, len(lines))]
+		label := previous
+		i, previous = end, ""
+		if isDiff(info, body) {
+			for j := 0; j+1 < len(body); j++ {
+				if strings.HasPrefix(body[j], "--- ") && strings.HasPrefix(body[j+1], "+++ ") {
+					k := diffEnd(body, j+2)
+					if err := p.diff(body[j:k]); err != nil {
+						return nil, err
+					}
+					j = k - 1
+				}
+			}
+			continue
+		}
+		name := fencePath(info)
+		if len(name) == 0 {
+			name = pathOf(label)
+		}
+		if len(name) == 0 && label == "Source Code:" {
+			name = stated
+		}
+		if rel, ok := p.resolve(name); ok {
+			contents := strings.Join(body, "\n")
+			if len(body) > 0 {
+				contents += "\n"
+			}
+			if err := p.set(rel, contents, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var patches []Patch
+	for _, patch := range p.patches {
+		if patch.Before == patch.After && !patch.Delete {
+			continue
+		}
+		status := ChangeModified
+		if !patch.Exists {
+			status = ChangeAdded
+		} else if patch.Delete {
+			status = ChangeRemoved
+		}
+		patch.Diff = change(patch.Path, status, patch.Before, patch.After).Diff
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// openingFence reports whether the line opens a fenced code block and returns its fence and info string
+func openingFence(line string) (string, string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return "", "", false
+	}
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 && !(c == "`" && strings.Contains(trimmed[n:], "`")) {
+			return trimmed[:n], strings.TrimSpace(trimmed[n:]), true
+		}
+	}
+	return "", "", false
+}
+
+// closingFence reports whether the line closes a block that was opened with the fence
+func closingFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence) && len(strings.Trim(trimmed, fence[:1])) == 0
+}
+
+// isDiff reports whether a fenced block holds unified diffs, because of the language in its info string or because
+// it starts like one
+func isDiff(info string, body []string) bool {
+	if lang, _, _ := strings.Cut(strings.ToLower(info), " "); lang == "diff" || lang == "patch" {
+		return true
+	}
+	for _, line := range body {
+		if len(strings.TrimSpace(line)) > 0 {
+			return strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "diff ")
+		}
+	}
+	return false
+}
+
+// fencePath returns the path in the info string of a fence, such as "go main.go", "go:main.go" or "main.go"
+func fencePath(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	if len(fields) > 1 {
+		return pathOf(fields[1])
+	}
+	if _, after, ok := strings.Cut(fields[0], ":"); ok {
+		return pathOf(after)
+	}
+	if strings.ContainsAny(fields[0], "./") {
+		return pathOf(fields[0])
+	}
+	return ""
+}
+
+// pathOf returns the path of a line that names nothing but a file, like a heading or a label in front of a block, or
+// an empty string when the line is prose
+func pathOf(line string) string {
+	s := strings.TrimSpace(line)
+	s = strings.TrimLeft(s, "#>-*+ ")
+	s = strings.Trim(s, "*_` ")
+	s = pathLabel.ReplaceAllString(s, "")
+	s = strings.TrimSuffix(strings.Trim(s, "*_` "), ":")
+	s = strings.Trim(s, "*_` ")
+	if len(s) == 0 || strings.ContainsAny(s, " \t") || !strings.ContainsAny(s, "./") || strings.HasSuffix(s, ".") {
+		return ""
+	}
+	return s
+}
+
+// diffEnd returns the index of the first line after the hunks of the unified diff whose header ends before start
+func diffEnd(lines []string, start int) int {
+	end := start
+	for end < len(lines) {
+		line := lines[end]
+		if strings.HasPrefix(line, "--- ") && end+1 < len(lines) && strings.HasPrefix(lines[end+1], "+++ ") {
+			break
+		}
+		if len(line) > 0 && !strings.ContainsRune(" +-@\\", rune(line[0])) {
+			break
+		}
+		end++
+	}
+	// blank lines are context lines inside of a diff, but not after its last hunk
+	for end > start && len(lines[end-1]) == 0 {
+		end--
+	}
+	return end
+}
+
+// diff applies a unified diff of a single file, from its "---" and "+++" header to its last hunk, to the contents of
+// the file it names
+func (p *patcher) diff(lines []string) error {
+	from, to := diffPath(lines[0][4:]), diffPath(lines[1][4:])
+	name := to
+	if to == "/dev/null" {
+		name = from
+	}
+	rel, ok := p.resolve(name)
+	if !ok {
+		return nil
+	}
+	before, err := p.current(rel)
+	if err != nil {
+		return err
+	}
+	if to == "/dev/null" {
+		return p.set(rel, "", true)
+	}
+	if from == "/dev/null" {
+		before = ""
+	}
+	after, err := applyHunks(before, parseHunks(lines[2:]))
+	if err != nil {
+		return fmt.Errorf("applying the diff of %s: %w", rel, err)
+	}
+	return p.set(rel, after, false)
+}
+
+// diffPath returns the path of a "---" or "+++" line of a unified diff without its "a/" or "b/" prefix and timestamp
+func diffPath(s string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return s
+	}
+	if rest, ok := strings.CutPrefix(s, "a/"); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(s, "b/"); ok {
+		return rest
+	}
+	return s
+}
+
+// parseHunks reads the hunks of a unified diff. Blank lines are taken as blank context lines, since responses often
+// drop the space in front of them, and the counts of the hunk headers are ignored for the same reason.
+func parseHunks(lines []string) []hunk {
+	var hunks []hunk
+	for _, line := range lines {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			hunks = append(hunks, hunk{start: start})
+			continue
+		}
+		if len(hunks) == 0 || strings.HasPrefix(line, "\\") {
+			continue
+		}
+		h := &hunks[len(hunks)-1]
+		op, text := byte(' '), line
+		if len(line) > 0 {
+			op, text = line[0], line[1:]
+		}
+		switch op {
+		case ' ':
+			h.old, h.new = append(h.old, text), append(h.new, text)
+		case '-':
+			h.old = append(h.old, text)
+		case '+':
+			h.new = append(h.new, text)
+		}
+	}
+	return hunks
+}
+
+// applyHunks replaces the old lines of every hunk in the contents with its new lines. Each hunk is searched for from
+// the line its header names outwards, first exactly and then ignoring trailing whitespace, so that a diff whose line
+// numbers drifted still applies.
+func applyHunks(contents string, hunks []hunk) (string, error) {
+	var lines []string
+	if len(contents) > 0 {
+		lines = strings.Split(strings.TrimSuffix(contents, "\n"), "\n")
+	}
+	offset := 0
+	for n, h := range hunks {
+		at := -1
+		for _, equal := range []func(a, b string) bool{
+			func(a, b string) bool { return a == b },
+			func(a, b string) bool { return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t") },
+		} {
+			if at = findLines(lines, h.old, max(0, h.start-1+offset), equal); at >= 0 {
+				break
+			}
+		}
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d does not match the file", n+1)
+		}
+		lines = append(lines[:at], append(append([]string{}, h.new...), lines[at+len(h.old):]...)...)
+		offset += len(h.new) - len(h.old)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// findLines returns the index of the first line of the closest run of lines equal to want around the line near, or -1
+func findLines(lines, want []string, near int, equal func(a, b string) bool) int {
+	matches := func(at int) bool {
+		if at < 0 || at+len(want) > len(lines) {
+			return false
+		}
+		for i, w := range want {
+			if !equal(lines[at+i], w) {
+				return false
+			}
+		}
+		return true
+	}
+	near = min(near, len(lines))
+	for d := 0; d <= len(lines); d++ {
+		if matches(near - d) {
+			return near - d
+		}
+		if d > 0 && matches(near+d) {
+			return near + d
+		}
+	}
+	return -1
+}
+
+// resolve returns the slash separated path of name relative to the workspace. The name is tried relative to the
+// workspace, relative to the working directory or as an absolute path, and without the directories of the workspace
+// that it starts with, such as "proj/main.go" for a workspace "proj", and the first of those that exists is used. A
+// bare file name that does not exist is matched to the only file in the workspace with that name, which is how the
+// headings of the DefaultTemplate name files. Otherwise the name is a new file relative to the workspace.
+func (p *patcher) resolve(name string) (string, bool) {
+	if len(name) == 0 {
+		return "", false
+	}
+	var candidates []string
+	name = filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	}
+	if abs, err := filepath.Abs(name); err == nil {
+		if rel, err := filepath.Rel(workspace(p.workspace), abs); err == nil {
+			candidates = append(candidates, rel)
+		}
+	}
+	dirs := strings.Split(filepath.ToSlash(workspace(p.workspace)), "/")
+	parts := strings.Split(filepath.ToSlash(name), "/")
+	for i := 1; i < len(parts) && i <= len(dirs); i++ {
+		if slices.Equal(parts[:i], dirs[len(dirs)-i:]) {
+			candidates = append(candidates, filepath.Join(parts[i:]...))
+		}
+	}
+	var local []string
+	for _, candidate := range candidates {
+		if !filepath.IsLocal(candidate) {
+			continue
+		}
+		rel := path.Clean(filepath.ToSlash(candidate))
+		if _, err := p.root.Stat(rel); err == nil {
+			return rel, true
+		}
+		if _, ok := p.index[rel]; ok {
+			return rel, true // a file that an earlier block of the response creates
+		}
+		local = append(local, rel)
+	}
+	if len(local) == 0 {
+		return "", false
+	}
+	if !strings.Contains(local[0], "/") {
+		if found, ok := p.find(local[0]); ok {
+			return found, true
+		}
+	}
+	return local[0], true
+}
+
+// find returns the only file named base in the workspace, skipping the directories that start with a dot
+func (p *patcher) find(base string) (string, bool) {
+	var found []string
+	_ = fs.WalkDir(p.root.FS(), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && name != "." && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && d.Name() == base {
+			found = append(found, name)
+		}
+		return nil
+	})
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// current returns the contents that the file at rel will have once the patches before it are applied
+func (p *patcher) current(rel string) (string, error) {
+	if i, ok := p.index[rel]; ok {
+		return p.patches[i].After, nil
+	}
+	b, err := fs.ReadFile(p.root.FS(), rel)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	return string(b), err
+}
+
+// set records the contents that the file at rel is patched to, or that it is deleted
+func (p *patcher) set(rel, after string, remove bool) error {
+	if i, ok := p.index[rel]; ok {
+		p.patches[i].After, p.patches[i].Delete = after, remove
+		return nil
+	}
+	patch := Patch{Path: rel, After: after, Delete: remove}
+	b, err := fs.ReadFile(p.root.FS(), rel)
+	switch {
+	case err == nil:
+		patch.Before, patch.Exists = string(b), true
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	p.index[rel] = len(p.patches)
+	p.patches = append(p.patches, patch)
+	return nil
+}
+
+// ApplyPatches writes the After of every Patch to its file in the workspace, or deletes it, after copying the file it
+// replaces to the same path inside backupDir. Every file must still have the contents it had when the Patch was made.
+// It returns the paths of the backups.
+func ApplyPatches(workspace string, patches []Patch, backupDir string) ([]string, error) {
+	root, err := os.OpenRoot(workspace)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	for _, patch := range patches {
+		b, err := fs.ReadFile(root.FS(), patch.Path)
+		if (err == nil) != patch.Exists || string(b) != patch.Before {
+			return nil, fmt.Errorf("%s changed since the patch was made", patch.Path)
+		}
+	}
+	var backups []string
+	for _, patch := range patches {
+		if patch.Exists {
+			backup := filepath.Join(backupDir, filepath.FromSlash(patch.Path))
+			if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+				return backups, err
+			}
+			info, err := root.Stat(patch.Path)
+			if err != nil {
+				return backups, err
+			}
+			if err := os.WriteFile(backup, []byte(patch.Before), info.Mode().Perm()); err != nil {
+				return backups, fmt.Errorf("backing up %s: %w", patch.Path, err)
+			}
+			backups = append(backups, backup)
+		}
+		if patch.Delete {
+			if err := root.Remove(patch.Path); err != nil {
+				return backups, err
+			}
+			continue
+		}
+		mode := os.FileMode(0644)
+		if info, err := root.Stat(patch.Path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if _, err := unpackFile(root, patch.Path, Result{File: File{Mode: mode}, Contents: patch.After}, true); err != nil {
+			return backups, fmt.Errorf("writing %s: %w", patch.Path, err)
+		}
+	}
+	return backups, nil
+}
@@ -0,0 +1,95 @@
+package summarize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// statedBlock returns a file block the way the DefaultTemplate renders the file at name
+func statedBlock(name, contents string) string {
+	return "## " + filepath.Base(name) + "\n\nThe `os.Stat` for the " + name + " is: \n\n```json\n{}\n```\n\n" +
+		"Source Code:\n\n```go\n" + contents + "```\n"
+}
+
+func TestPatchesResolve(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"proj/main.go":       "package main\n",
+		"proj/svc/svc.go":    "package svc\n",
+		"proj/proj/proj.go":  "package proj\n",
+		"proj/docs/guide.md": "# Guide\n",
+	})
+	t.Chdir(dir)
+	tests := []struct {
+		name string
+		path string
+		want string
+		new  bool
+	}{
+		{"relative to the workspace", "main.go", "main.go", false},
+		{"relative to the working directory", "proj/main.go", "main.go", false},
+		{"absolute", filepath.Join(dir, "proj", "svc", "svc.go"), "svc/svc.go", false},
+		{"bare name of a nested file", "svc.go", "svc/svc.go", false},
+		{"directory named after the workspace", "proj/proj.go", "proj/proj.go", false},
+		{"new file", "pkg/new.go", "pkg/new.go", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches, err := Patches("proj", statedBlock(tt.path, "package changed\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(patches) != 1 {
+				t.Fatalf("Patches of %s returned %d patches, want 1", tt.path, len(patches))
+			}
+			if patches[0].Path != tt.want || patches[0].Exists == tt.new {
+				t.Errorf("Patches of %s = %s (exists %v), want %s (exists %v)", tt.path, patches[0].Path, patches[0].Exists, tt.want, !tt.new)
+			}
+		})
+	}
+}
+
+func TestPatchesRefuseTraversal(t *testing.T) {
+	dir := t.TempDir()
+	workspace, outside := filepath.Join(dir, "proj"), filepath.Join(dir, "outside")
+	writeTree(t, dir, map[string]string{
+		"proj/main.go":       "package main\n",
+		"outside/escaped.go": "package escaped\n",
+	})
+	responses := map[string]string{
+		"parent block":   "```go ../outside/escaped.go\npackage changed\n```\n",
+		"absolute block": "```go " + filepath.Join(outside, "escaped.go") + "\npackage changed\n```\n",
+		"stated parent":  statedBlock("../outside/escaped.go", "package changed\n"),
+		"parent diff":    "--- a/../outside/escaped.go\n+++ b/../outside/escaped.go\n@@ -1 +1 @@\n-package escaped\n+package changed\n",
+		"deleting diff":  "--- a/../outside/escaped.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package escaped\n",
+	}
+	for name, response := range responses {
+		t.Run(name, func(t *testing.T) {
+			patches, err := Patches(workspace, response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(patches) > 0 {
+				t.Errorf("Patches returned %+v for a path outside of the workspace", patches)
+			}
+		})
+	}
+
+	t.Run("symlink", func(t *testing.T) {
+		if err := os.Symlink(outside, filepath.Join(workspace, "link")); err != nil {
+			t.Skip(err)
+		}
+		patches := []Patch{{Path: "link/escaped.go", Before: "package escaped\n", After: "package changed\n", Exists: true}}
+		if _, err := ApplyPatches(workspace, patches, filepath.Join(dir, "backups")); err == nil {
+			t.Error("ApplyPatches through a symlink out of the workspace returned no error")
+		}
+		b, err := os.ReadFile(filepath.Join(outside, "escaped.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "package escaped\n" {
+			t.Errorf("ApplyPatches wrote %q through a symlink out of the workspace", b)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package summarize
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// loadCache reads the cache at path for the opts, starting an empty cache when the file does not exist yet or was
+// written by a different version or with Options that change what is cached
+func loadCache(path string, opts Options) (*cache, error) {
+	fingerprint := cacheFingerprint(opts)
+	c, err := readCache(path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && c.Fingerprint != fingerprint) {
+		return &cache{Fingerprint: fingerprint, Entries: make(map[string]cacheEntry)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// readCache decodes the cache at path
+func readCache(path string) (*cache, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var c cache
+	if err := gob.NewDecoder(f).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decoding cache %s: %w", path, err)
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]cacheEntry)
+	}
+	return &c, nil
+}
+
+// save writes the cache to path through a temporary file so that an interrupted run never leaves a partial cache
+func (c *cache) save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(c); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("encoding cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cacheFingerprint hashes the Options that change the contents, reasons, redactions, tokens or rendering of a
+// cacheEntry so that a cache is only reused by runs that would produce the same entries
+func cacheFingerprint(opts Options) string {
+	b, _ := json.Marshal(struct {
+		Version         int
+		SkipBinary      bool
+		SkipInvalidUTF8 bool
+		MaxLineLength   int
+		SkipGenerated   bool
+		Redact          bool
+		MaxFileBytes    int64
+		MaxFileLines    int
+		HeadLines       int
+		TailLines       int
+		Tokenizer       string
+		Format          string
+		Template        string
+		Aliases         []string
+	}{
+		Version:         cacheVersion,
+		SkipBinary:      opts.SkipBinary,
+		SkipInvalidUTF8: opts.SkipInvalidUTF8,
+		MaxLineLength:   opts.MaxLineLength,
+		SkipGenerated:   opts.SkipGenerated,
+		Redact:          opts.Redact || opts.FailOnSecret,
+		MaxFileBytes:    opts.MaxFileBytes,
+		MaxFileLines:    opts.MaxFileLines,
+		HeadLines:       opts.HeadLines,
+		TailLines:       opts.TailLines,
+		Tokenizer:       fmt.Sprintf("%s %v", opts.Tokenizer.Name(), opts.Tokenizer),
+		Format:          opts.Format,
+		Template:        opts.Template,
+		Aliases:         aliases(opts.Roots),
+	})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// cached returns the cacheEntry of path when the file still has the size, modification time and mode it was cached
+// with, so that it can be summarized without being read
+func (r *run) cached(path string, info fs.FileInfo) (cacheEntry, bool) {
+	if r.cache == nil {
+		return cacheEntry{}, false
+	}
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	entry, ok := r.cache.Entries[cacheKey(path)]
+	if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) || entry.Mode != info.Mode() {
+		return cacheEntry{}, false
+	}
+	r.cacheHits++
+	return entry, true
+}
+
+// cachedContent returns the cacheEntry of path when it was cached with the same hash, such as after a checkout that
+// touched the file without changing it, updated to the size, modification time and mode of the file on disk
+func (r *run) cachedContent(path string, info fs.FileInfo, hash string) (cacheEntry, bool) {
+	if r.cache == nil {
+		return cacheEntry{}, false
+	}
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	entry, ok := r.cache.Entries[cacheKey(path)]
+	if !ok || entry.Hash != hash {
+		return cacheEntry{}, false
+	}
+	r.cacheHits++
+	entry.Size, entry.ModTime, entry.Mode, entry.Cost = info.Size(), info.ModTime(), info.Mode(), 0
+	return entry, true
+}
+
+// store records the cacheEntry of path to be saved at the end of the run
+func (r *run) store(path string, entry cacheEntry) {
+	if r.cache == nil {
+		return
+	}
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	r.cache.Entries[cacheKey(path)] = entry
+}
+
+// cacheKey returns the absolute path that the cacheEntry of path is kept under, so that the cache can be reused and
+// pruned from any working directory
+func cacheKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// PruneCache removes the entries of the cache at path whose files were deleted or changed since they were cached and
+// returns how many entries were kept and pruned. The entries are kept under absolute paths, so the cache can be pruned
+// from any working directory.
+func PruneCache(path string) (kept, pruned int, err error) {
+	c, err := readCache(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	for p, entry := range c.Entries {
+		info, err := os.Stat(p)
+		if err != nil || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) || entry.Mode != info.Mode() {
+			delete(c.Entries, p)
+			pruned++
+			continue
+		}
+		kept++
+	}
+	return kept, pruned, c.save(path)
+}
@@ -0,0 +1,149 @@
+package summarize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cachedRun summarizes the opts and returns the Contents of the Result of each path
+func cachedRun(t *testing.T, opts Options) map[string]string {
+	t.Helper()
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := make(map[string]string)
+	for _, result := range summary.Results {
+		contents[result.Path] = result.Contents
+	}
+	return contents
+}
+
+// tamper replaces the Contents that the cache at path holds for the file, so that a run that reuses the entry
+// instead of reading the file is told apart by its Contents
+func tamper(t *testing.T, path, file, contents string) {
+	t.Helper()
+	c, err := readCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := c.Entries[file]
+	if !ok {
+		t.Fatalf("the cache holds no entry for %s: %v", file, c.Entries)
+	}
+	entry.Contents = contents
+	c.Entries[file] = entry
+	if err := c.save(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"proj/main.go": "package main\n"})
+	main := filepath.Join(dir, "proj", "main.go")
+	opts := treeOptions(filepath.Join(dir, "proj"))
+	opts.IncludeExt, opts.CacheFile = []string{"go"}, filepath.Join(dir, CacheFilename)
+	if got := cachedRun(t, opts)["main.go"]; got != "package main\n" {
+		t.Fatalf("the first run summarized main.go as %q", got)
+	}
+
+	t.Run("hit on an unchanged file", func(t *testing.T) {
+		tamper(t, opts.CacheFile, main, "package cached\n")
+		if got := cachedRun(t, opts)["main.go"]; got != "package cached\n" {
+			t.Errorf("main.go was summarized as %q instead of reusing its cache entry", got)
+		}
+	})
+
+	t.Run("fingerprint of changed options", func(t *testing.T) {
+		tamper(t, opts.CacheFile, main, "package cached\n")
+		before, err := readCache(opts.CacheFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changed := opts
+		changed.MaxFileLines = 100
+		if got := cachedRun(t, changed)["main.go"]; got != "package main\n" {
+			t.Errorf("main.go was summarized as %q from a cache written with other options", got)
+		}
+		c, err := readCache(opts.CacheFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Fingerprint == before.Fingerprint {
+			t.Error("the cache was not rewritten with the fingerprint of the changed options")
+		}
+		cachedRun(t, opts)
+	})
+
+	t.Run("hash when only the modification time changes", func(t *testing.T) {
+		tamper(t, opts.CacheFile, main, "package cached\n")
+		touched := time.Now().Add(time.Hour).Truncate(time.Second)
+		if err := os.Chtimes(main, touched, touched); err != nil {
+			t.Fatal(err)
+		}
+		if got := cachedRun(t, opts)["main.go"]; got != "package cached\n" {
+			t.Errorf("main.go was summarized as %q instead of reusing the entry of the same hash", got)
+		}
+		c, err := readCache(opts.CacheFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.Entries[main].ModTime.Equal(touched) {
+			t.Errorf("the entry of main.go was cached at %v, want the new modification time %v", c.Entries[main].ModTime, touched)
+		}
+	})
+
+	t.Run("changed contents", func(t *testing.T) {
+		tamper(t, opts.CacheFile, main, "package cached\n")
+		writeTree(t, dir, map[string]string{"proj/main.go": "package changed\n"})
+		later := time.Now().Add(2 * time.Hour)
+		if err := os.Chtimes(main, later, later); err != nil {
+			t.Fatal(err)
+		}
+		if got := cachedRun(t, opts)["main.go"]; got != "package changed\n" {
+			t.Errorf("main.go was summarized as %q after it changed", got)
+		}
+	})
+}
+
+func TestPruneCache(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"proj/kept.go":    "package kept\n",
+		"proj/changed.go": "package changed\n",
+		"proj/deleted.go": "package deleted\n",
+		"elsewhere/x.txt": "",
+	})
+	t.Chdir(dir)
+	opts := treeOptions("proj")
+	opts.IncludeExt, opts.CacheFile = []string{"go"}, filepath.Join(dir, CacheFilename)
+	cachedRun(t, opts)
+
+	writeTree(t, dir, map[string]string{"proj/changed.go": "package changed\n\nvar x int\n"})
+	if err := os.Remove(filepath.Join(dir, "proj", "deleted.go")); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(dir, "elsewhere"))
+	kept, pruned, err := PruneCache(opts.CacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept != 1 || pruned != 2 {
+		t.Errorf("PruneCache kept %d and pruned %d entries, want 1 and 2", kept, pruned)
+	}
+	c, err := readCache(opts.CacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Entries[filepath.Join(dir, "proj", "kept.go")]; !ok || len(c.Entries) != 1 {
+		t.Errorf("PruneCache left %v, want the entry of kept.go", c.Entries)
+	}
+}
@@ -0,0 +1,161 @@
+package summarize
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// chunk fills Chunks with the received results in order instead of leaving out the ones over the budgets. Every Chunk
+// repeats the header with its own table of contents and stays under the Options.MaxOutputSize and Options.MaxTokens,
+// while a file is only split across chunks when it is over the limits by itself.
+func (r *run) chunk(received []Result, h header) {
+	// the footer is estimated with every truncated file to find the files that need to be split, then rendered again
+	// with the files that were placed
+	for _, in := range received {
+		if in.Truncation != nil {
+			r.truncated = append(r.truncated, *in.Truncation)
+		}
+	}
+	foot, err := r.renderer.footer(r.report())
+	if err != nil {
+		r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering footer: %w", err)})
+	}
+	var placed []Result
+	for _, in := range received {
+		if r.fits(h, []Result{in}, foot) {
+			placed = append(placed, in)
+			continue
+		}
+		pieces := r.split(h, in, foot)
+		if len(pieces) == 0 {
+			r.debugf("chunk: %s has a line over the limits, skipping\n", in.Path)
+			r.omit(in.Path, in.Size, OmitChunkLimit)
+			continue
+		}
+		r.debugf("chunk: split %s into %d pieces\n", in.Path, len(pieces))
+		placed = append(placed, pieces...)
+	}
+
+	r.truncated = r.truncated[:0]
+	for _, in := range placed {
+		if in.Truncation != nil && (in.Piece == nil || in.Piece.Index == 1) {
+			r.truncated = append(r.truncated, *in.Truncation)
+		}
+	}
+	if foot, err = r.renderer.footer(r.report()); err != nil {
+		r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering footer: %w", err)})
+	}
+
+	var groups [][]Result
+	var current []Result
+	for _, in := range placed {
+		if len(current) > 0 && !r.fits(h, append(current[:len(current):len(current)], in), foot) {
+			groups = append(groups, current)
+			current = nil
+		}
+		current = append(current, in)
+	}
+	if len(current) > 0 || len(groups) == 0 {
+		groups = append(groups, current)
+	}
+
+	empty, err := r.renderer.footer(footer{Truncated: []Truncation{}, Redactions: []Redaction{}, Omitted: []Omission{}, Errors: []FileError{}})
+	if err != nil {
+		r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering footer: %w", err)})
+	}
+	separator := r.renderer.separator()
+	for i, group := range groups {
+		part := h
+		part.Files, part.Part, part.Parts = files(group), i+1, len(groups)
+		head, err := r.renderer.header(part)
+		if err != nil {
+			r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering header of part %d: %w", i+1, err)})
+		}
+		var buf bytes.Buffer
+		buf.Write(head)
+		for j, in := range group {
+			if j > 0 {
+				buf.Write(separator)
+			}
+			buf.Write(in.rendered)
+		}
+		if i == len(groups)-1 {
+			buf.Write(foot)
+		} else {
+			buf.Write(empty)
+		}
+		c := Chunk{Part: i + 1, Files: part.Files, Contents: buf.Bytes(), Size: int64(buf.Len())}
+		c.Tokens = r.opts.Tokenizer.Count(c.Contents)
+		r.debugf("chunk: part %d of %d has %d files, %d bytes and %d tokens\n", c.Part, len(groups), len(group), c.Size, c.Tokens)
+		r.chunks = append(r.chunks, c)
+		r.rendered = append(r.rendered, group...)
+		r.tokens += c.Tokens
+	}
+}
+
+// fits reports whether a chunk of the results, with the header listing them and the footer, stays under the
+// Options.MaxOutputSize and Options.MaxTokens
+func (r *run) fits(h header, results []Result, foot []byte) bool {
+	h.Files, h.Part, h.Parts = files(results), chunkPlaceholder, chunkPlaceholder
+	head, err := r.renderer.header(h)
+	if err != nil {
+		return false
+	}
+	separator := r.renderer.separator()
+	size := int64(len(head) + len(foot) + len(separator)*(len(results)-1))
+	tokens := r.opts.Tokenizer.Count(head) + r.opts.Tokenizer.Count(foot) + r.opts.Tokenizer.Count(separator)*(len(results)-1)
+	for _, in := range results {
+		size += int64(len(in.rendered))
+		tokens += in.cost
+	}
+	return size < r.opts.MaxOutputSize && (r.opts.MaxTokens == 0 || tokens <= r.opts.MaxTokens)
+}
+
+// split renders the contents of a result that is over the limits by itself as pieces of consecutive lines that each
+// fit in a chunk of their own, or returns nil when a single line is over the limits
+func (r *run) split(h header, in Result, foot []byte) []Result {
+	lines := strings.SplitAfter(in.Contents, "\n")
+	if len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	var pieces []Result
+	for first := 0; first < len(lines); {
+		// the largest number of lines that fit is found with a binary search, rendering only a few candidates
+		n := sort.Search(len(lines)-first, func(n int) bool {
+			piece, err := r.piece(in, lines, first, first+n+1, chunkPlaceholder, chunkPlaceholder)
+			return err != nil || !r.fits(h, []Result{piece}, foot)
+		})
+		if n == 0 {
+			return nil
+		}
+		pieces = append(pieces, Result{File: File{Piece: &Piece{FirstLine: first + 1, LastLine: first + n}}})
+		first += n
+	}
+	for i := range pieces {
+		p := pieces[i].File.Piece
+		piece, err := r.piece(in, lines, p.FirstLine-1, p.LastLine, i+1, len(pieces))
+		if err != nil {
+			r.errs.Add(&Error{Kind: KindRender, Path: in.Path, Err: err})
+			return nil
+		}
+		pieces[i] = piece
+	}
+	return pieces
+}
+
+// piece renders the lines from first up to last of the result as the index of count pieces
+func (r *run) piece(in Result, lines []string, first, last, index, count int) (Result, error) {
+	piece := in
+	piece.Piece = &Piece{Index: index, Count: count, FirstLine: first + 1, LastLine: last}
+	piece.Contents = strings.Join(lines[first:last], "")
+	piece.Tokens = r.opts.Tokenizer.Count([]byte(piece.Contents))
+	rendered, err := r.renderer.file(piece)
+	if err != nil {
+		return Result{}, err
+	}
+	piece.rendered = rendered
+	piece.cost = r.opts.Tokenizer.Count(rendered)
+	return piece, nil
+}
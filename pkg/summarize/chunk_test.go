@@ -0,0 +1,80 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunChunks(t *testing.T) {
+	dir := t.TempDir()
+	var big strings.Builder
+	for i := 1; i <= 120; i++ {
+		fmt.Fprintf(&big, "// line %03d of big.go\n", i)
+	}
+	files := map[string]string{
+		"a.go":   "package a\n",
+		"big.go": big.String(),
+		"c.go":   "package c\n",
+	}
+	writeTree(t, dir, files)
+	opts := treeOptions(dir)
+	opts.IncludeExt, opts.Format, opts.Chunk, opts.MaxOutputSize = []string{"go"}, FormatJSON, true, 2048
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Chunks) < 3 {
+		t.Fatalf("Run split the summary into %d chunks, want big.go split across at least 2 of them", len(summary.Chunks))
+	}
+	if len(summary.Contents) > 0 {
+		t.Errorf("Run left %d bytes in the Contents of a chunked summary", len(summary.Contents))
+	}
+	var size int64
+	var results []Result
+	pieces, count := 0, 0
+	for i, c := range summary.Chunks {
+		size += c.Size
+		if c.Part != i+1 {
+			t.Errorf("chunk %d is numbered as part %d", i+1, c.Part)
+		}
+		if c.Size != int64(len(c.Contents)) || c.Size >= opts.MaxOutputSize {
+			t.Errorf("part %d has a Size of %d for %d bytes, want it under %d", c.Part, c.Size, len(c.Contents), opts.MaxOutputSize)
+		}
+		doc, err := Parse(c.Contents)
+		if err != nil {
+			t.Fatalf("parsing part %d: %v", c.Part, err)
+		}
+		for _, f := range c.Files {
+			if f.Path != "big.go" {
+				continue
+			}
+			pieces++
+			if f.Piece == nil || f.Piece.Index != pieces {
+				t.Fatalf("part %d holds the piece %+v of big.go, want piece %d", c.Part, f.Piece, pieces)
+			}
+			count = f.Piece.Count
+		}
+		results = append(results, doc.Results...)
+	}
+	if summary.Size != size || size == 0 {
+		t.Errorf("Summary.Size = %d, want the %d bytes of its chunks", summary.Size, size)
+	}
+	if pieces < 2 || pieces != count {
+		t.Errorf("big.go was split into %d pieces numbered out of %d", pieces, count)
+	}
+	joined := join(results)
+	if len(joined) != len(files) {
+		t.Fatalf("the parts hold %d files, want %d", len(joined), len(files))
+	}
+	for _, in := range joined {
+		if in.Contents != files[in.Path] {
+			t.Errorf("the parts hold %s as %q, want %q", in.Path, in.Contents, files[in.Path])
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package summarize
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// interpreterVersion matches the version at the end of an interpreter such as python3.12
+var interpreterVersion = regexp.MustCompile(`[0-9.]+$`)
+
+// Classify returns the type of the file at path that decides whether it is included and how its contents are fenced:
+// the lowercase extension of the path, the type of a special file name such as Makefile or Dockerfile, or the extension
+// of the interpreter that the shebang at the start of head runs, such as sh for "#!/bin/bash". The head is only used
+// when the path has neither an extension nor a special name, and the type is empty when none of them apply.
+func Classify(path string, head []byte) string {
+	name := filepath.Base(path)
+	if kind, ok := specialNames[name]; ok {
+		return kind
+	}
+	for _, prefix := range []string{"Dockerfile.", "Containerfile."} {
+		if strings.HasPrefix(name, prefix) {
+			return "Dockerfile"
+		}
+	}
+	if ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), ".")); len(ext) > 0 {
+		return ext
+	}
+	return shebang(head)
+}
+
+// ClassifyFile returns the Classify type of the file at path, reading the start of the file for its shebang only when
+// the path has neither an extension nor a special name
+func ClassifyFile(path string) (string, error) {
+	if kind := Classify(path, nil); len(kind) > 0 {
+		return kind, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, shebangLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	return Classify(path, head[:n]), nil
+}
+
+// shebang returns the extension of the interpreter that the "#!" line at the start of head runs, following
+// "/usr/bin/env" to the interpreter it names, or an empty string when head has no shebang of a known interpreter
+func shebang(head []byte) string {
+	line, ok := bytes.CutPrefix(head, []byte("#!"))
+	if !ok {
+		return ""
+	}
+	line, _, _ = bytes.Cut(line, []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) > 0 && filepath.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		// skip the options of env, such as -S
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+			fields = fields[1:]
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if ext, ok := interpreters[interpreter]; ok {
+		return ext
+	}
+	return interpreters[interpreterVersion.ReplaceAllString(interpreter, "")]
+}
@@ -0,0 +1,114 @@
+package summarize
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		head string
+		want string
+	}{
+		{name: "extension", path: "cmd/main.go", want: "go"},
+		{name: "uppercase extension", path: "README.MD", want: "md"},
+		{name: "last extension", path: "archive.tar.gz", want: "gz"},
+		{name: "dotfile", path: ".gitignore", want: "gitignore"},
+		{name: "makefile", path: "Makefile", want: "Makefile"},
+		{name: "lowercase makefile", path: "build/makefile", want: "Makefile"},
+		{name: "dockerfile", path: "Dockerfile", want: "Dockerfile"},
+		{name: "dockerfile variant", path: "deploy/Dockerfile.prod", want: "Dockerfile"},
+		{name: "containerfile", path: "Containerfile", want: "Dockerfile"},
+		{name: "extension wins over shebang", path: "run.py", head: "#!/bin/bash\n", want: "py"},
+		{name: "bash shebang", path: "bin/deploy", head: "#!/bin/bash\nset -e\n", want: "sh"},
+		{name: "env shebang", path: "bin/tool", head: "#!/usr/bin/env python3\n", want: "py"},
+		{name: "versioned interpreter", path: "bin/tool", head: "#!/usr/bin/python3.12 -u\n", want: "py"},
+		{name: "env options", path: "bin/tool", head: "#!/usr/bin/env -S node --no-warnings\n", want: "js"},
+		{name: "unknown interpreter", path: "bin/tool", head: "#!/usr/local/bin/fish\n", want: ""},
+		{name: "no shebang", path: "LICENSE", head: "MIT License\n", want: ""},
+		{name: "shebang not at start", path: "bin/tool", head: "\n#!/bin/sh\n", want: ""},
+		{name: "empty head", path: "bin/tool", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.path, []byte(tt.head)); got != tt.want {
+				t.Errorf("Classify(%q, %q) = %q, want %q", tt.path, tt.head, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"deploy":  "#!/bin/sh\necho deploy\n",
+		"LICENSE": "MIT License\n",
+		"main.go": "#!/bin/sh\n",
+		"empty":   "",
+	}
+	want := map[string]string{"deploy": "sh", "LICENSE": "", "main.go": "go", "empty": ""}
+	writeTree(t, dir, files)
+	for name, kind := range want {
+		got, err := ClassifyFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ClassifyFile(%q) returned %v", name, err)
+		}
+		if got != kind {
+			t.Errorf("ClassifyFile(%q) = %q, want %q", name, got, kind)
+		}
+	}
+	if _, err := ClassifyFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ClassifyFile of a missing file without an extension did not return an error")
+	}
+}
+
+func TestRunClassifiesEachFileOnce(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":      "package main\n",
+		"Makefile":     "all:\n\tgo build\n",
+		"Dockerfile":   "FROM scratch\n",
+		"bin/release":  "#!/usr/bin/env bash\necho release\n",
+		"notes.txt":    "not included\n",
+		"LICENSE":      "MIT License\n",
+		"web/index.js": "console.log(1)\n",
+	}
+	writeTree(t, dir, files)
+	opts := treeOptions(dir)
+	opts.IncludeExt = []string{"go", "sh", "Makefile", "Dockerfile", "js"}
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"main.go":      "go",
+		"Makefile":     "Makefile",
+		"Dockerfile":   "Dockerfile",
+		"bin/release":  "sh",
+		"web/index.js": "js",
+	}
+	got := make(map[string]string)
+	for _, in := range summary.Results {
+		if _, ok := got[in.Path]; ok {
+			t.Errorf("%s was summarized more than once", in.Path)
+		}
+		got[in.Path] = in.Language
+	}
+	for rel, language := range want {
+		if got[rel] != language {
+			t.Errorf("%s has language %q, want %q", rel, got[rel], language)
+		}
+	}
+	for rel := range got {
+		if _, ok := want[rel]; !ok {
+			t.Errorf("%s of a type that is not included was summarized", rel)
+		}
+	}
+}
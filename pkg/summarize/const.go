@@ -0,0 +1,235 @@
+package summarize
+
+const (
+	// ProjectName is rendered in the header of every Summary
+	ProjectName string = "github.com/andreimerlescu/summarize"
+
+	// DefaultMaxOutputSize is the Options.MaxOutputSize used when none is provided
+	DefaultMaxOutputSize int64 = 1_776_369
+
+	// DefaultMaxFiles is the Options.MaxFiles used when none is provided
+	DefaultMaxFiles int = 369
+
+	// DefaultHeadLines is the Options.HeadLines used when neither HeadLines nor TailLines are provided
+	DefaultHeadLines int = 100
+
+	// DefaultTailLines is the Options.TailLines used when neither HeadLines nor TailLines are provided
+	DefaultTailLines int = 20
+
+	// CacheFilename is the name of the Options.CacheFile kept in the output directory of the summaries
+	CacheFilename string = ".summarize.cache"
+
+	// GitIgnoreFile is read from every directory when Options.GitIgnore is enabled
+	GitIgnoreFile string = ".gitignore"
+
+	// DockerIgnoreFile is read from the root of the SourceDir when Options.DockerIgnore is enabled
+	DockerIgnoreFile string = ".dockerignore"
+
+	// SummarizeIgnoreFile is read from the root of the SourceDir when Options.SummarizeIgnore is enabled
+	SummarizeIgnoreFile string = ".summarizeignore"
+
+	// GitModeTracked selects every file tracked in the index of the git repository
+	GitModeTracked string = "tracked"
+
+	// GitModeChanged selects the tracked files that changed since Options.GitSince, leaving out the untracked files
+	GitModeChanged string = "changed"
+
+	// GitModeStaged selects the files whose staged contents differ from HEAD
+	GitModeStaged string = "staged"
+
+	// TokenizerBPE is the name of the BPETokenizer
+	TokenizerBPE string = "bpe"
+
+	// TokenizerChars is the name of the CharTokenizer
+	TokenizerChars string = "chars"
+
+	// DefaultCharsPerToken is the CharTokenizer ratio used when none is provided
+	DefaultCharsPerToken float64 = 4
+
+	// OmitSizeBudget is the Omission.Reason of a file that would exceed the Options.MaxOutputSize
+	OmitSizeBudget string = "size budget"
+
+	// OmitTokenBudget is the Omission.Reason of a file that would exceed the Options.MaxTokens
+	OmitTokenBudget string = "token budget"
+
+	// OmitReadError is the Omission.Reason of a file that could not be read
+	OmitReadError string = "read error"
+
+	// OmitExcluded is the Omission.Reason of a file that was excluded after it was inspected
+	OmitExcluded string = "excluded"
+
+	// OmitChunkLimit is the Omission.Reason of a file with a line over the Options.MaxOutputSize or Options.MaxTokens
+	// that Options.Chunk cannot split
+	OmitChunkLimit string = "chunk limit"
+
+	// OmitBinary is the Omission.Reason of a file skipped by Options.SkipBinary
+	OmitBinary string = "binary"
+
+	// OmitInvalidUTF8 is the Omission.Reason of a file skipped by Options.SkipInvalidUTF8
+	OmitInvalidUTF8 string = "invalid utf-8"
+
+	// OmitLongLines is the Omission.Reason of a file skipped by Options.MaxLineLength
+	OmitLongLines string = "long lines"
+
+	// OmitGenerated is the Omission.Reason of a file skipped by Options.SkipGenerated
+	OmitGenerated string = "generated"
+
+	// RedactPrivateKey is the Redaction.Kind of a PEM or PGP private key block
+	RedactPrivateKey string = "private-key"
+
+	// RedactAWSAccessKey is the Redaction.Kind of an AWS access key ID
+	RedactAWSAccessKey string = "aws-access-key"
+
+	// RedactAWSSecretKey is the Redaction.Kind of an AWS secret access key assigned to an aws_secret_access_key
+	RedactAWSSecretKey string = "aws-secret-key"
+
+	// RedactGitHubToken is the Redaction.Kind of a GitHub personal access, OAuth, app or refresh token
+	RedactGitHubToken string = "github-token"
+
+	// RedactJWT is the Redaction.Kind of a JSON Web Token
+	RedactJWT string = "jwt"
+
+	// RedactPassword is the Redaction.Kind of a password, secret or token assigned in a .env style file
+	RedactPassword string = "password"
+
+	// RedactHighEntropy is the Redaction.Kind of a quoted string that looks randomly generated
+	RedactHighEntropy string = "high-entropy"
+
+	// FormatMarkdown renders the summary as a Markdown document with a fenced block for each file
+	FormatMarkdown string = "markdown"
+
+	// FormatJSON renders the summary as a JSON object with a record for each file
+	FormatJSON string = "json"
+
+	// FormatYAML renders the summary as a YAML document with a record for each file
+	FormatYAML string = "yaml"
+
+	// FormatJSONL renders the summary as JSON Lines with a line for each file between a summary and a report line
+	FormatJSONL string = "jsonl"
+
+	// FormatXML renders the summary as a documents element with a document element for each file
+	FormatXML string = "xml"
+
+	// Instructions tell the model reading the summary how to answer questions about the workspace
+	Instructions string = "AI Instructions are the user requests that you analyze their project workspace " +
+		"as provided here by filename followed by the contents. You are to answer their " +
+		"question using the source code provided as the basis of your responses. You are to " +
+		"completely modify each individual file as per-the request and provide the completely " +
+		"updated form of the file. Do not abbreviate the file, and if the file is excessive in " +
+		"length, then print the entire contents in your response with your updates to the " +
+		"specific components while retaining all existing functionality and maintaining comments " +
+		"within the code."
+
+	// ChangeAdded is the Change.Status of a file that is only in the newer Document
+	ChangeAdded string = "added"
+
+	// ChangeRemoved is the Change.Status of a file that is only in the older Document
+	ChangeRemoved string = "removed"
+
+	// ChangeModified is the Change.Status of a file whose contents differ between the Documents
+	ChangeModified string = "modified"
+
+	// OrderPath renders the files sorted by their path relative to the SourceDir
+	OrderPath string = "path"
+
+	// OrderTree renders the files depth first, listing the files of a directory before its subdirectories
+	OrderTree string = "tree"
+
+	// OrderSize renders the smallest files first
+	OrderSize string = "size"
+
+	// OrderModified renders the most recently modified files first
+	OrderModified string = "mtime"
+
+	// OrderPriority renders the files matching the Options.Priority globs first
+	OrderPriority string = "priority"
+
+	// DotsInclude is the Options.Dots policy that summarizes dotfiles and walks dot-directories like any other path
+	DotsInclude string = "include"
+
+	// DotsExcludeFiles is the Options.Dots policy that skips the files whose name starts with a "."
+	DotsExcludeFiles string = "exclude-files"
+
+	// DotsExcludeDirs is the Options.Dots policy that does not walk the directories whose name starts with a "."
+	DotsExcludeDirs string = "exclude-dirs"
+
+	// DotsExclude is the Options.Dots policy of both DotsExcludeFiles and DotsExcludeDirs
+	DotsExclude string = "exclude"
+
+	// RuleSkip is the Explanation.Rule of a path that contains one of the Options.SkipContains substrings
+	RuleSkip string = "skip"
+
+	// RuleDots is the Explanation.Rule of a path left out by the Options.Dots policy
+	RuleDots string = "dots"
+
+	// RuleIgnoreFile is the Explanation.Rule of a path matched by a .gitignore, .dockerignore or .summarizeignore
+	RuleIgnoreFile string = "ignore file"
+
+	// RuleExcludeRegex is the Explanation.Rule of a path matched by one of the Options.ExcludeRegex
+	RuleExcludeRegex string = "exclude regex"
+
+	// RuleExcludeGlob is the Explanation.Rule of a path whose last matching Options.ExcludeGlob excludes it
+	RuleExcludeGlob string = "exclude glob"
+
+	// RuleIncludeGlob is the Explanation.Rule of a file that matches one of the Options.IncludeGlob, or none of them
+	RuleIncludeGlob string = "include glob"
+
+	// RuleExcludeExt is the Explanation.Rule of a file whose Classify type is one of the Options.ExcludeExt
+	RuleExcludeExt string = "exclude ext"
+
+	// RuleIncludeExt is the Explanation.Rule of a file whose Classify type is one of the Options.IncludeExt, or none
+	// of them
+	RuleIncludeExt string = "include ext"
+
+	// RuleContent is the Explanation.Rule of a Listing whose file passed the other rules but is skipped once it is
+	// read, with the Omission.Reason as the Match
+	RuleContent string = "content"
+
+	// KindConfig is the Error.Kind of Options that New refuses
+	KindConfig string = "config"
+
+	// KindWalk is the Error.Kind of a failure to walk the SourceDir or to select its files with Options.Git
+	KindWalk string = "walk"
+
+	// KindRead is the Error.Kind of a file that could not be read
+	KindRead string = "read"
+
+	// KindRender is the Error.Kind of a file, header or footer that could not be rendered or of a summary that could
+	// not be written
+	KindRender string = "render"
+)
+
+const (
+	// packPriorityWeight is multiplied by the position of the matching Options.Priority glob from the end of the list
+	packPriorityWeight int = 100
+
+	// packEntryPointBoost is added to files matching the Options.EntryPoints
+	packEntryPointBoost int = 50
+
+	// packDemotePenalty is subtracted from files matching the Options.Demote
+	packDemotePenalty int = 50
+
+	// sniffLength is how many leading bytes Options.SkipBinary searches for a NUL byte, the same as git
+	sniffLength int = 8000
+
+	// shebangLength is how many leading bytes ClassifyFile reads of a file without an extension to find its shebang
+	shebangLength int = 256
+
+	// sniffGeneratedLines is how many leading lines Options.SkipGenerated searches for a generated code marker
+	sniffGeneratedLines int = 40
+
+	// chunkPlaceholder is the part number used while measuring the header of a chunk before the parts are counted
+	chunkPlaceholder int = 999_999
+
+	// diffContext is how many unchanged lines surround every hunk of a Change.Diff
+	diffContext int = 3
+
+	// cacheVersion is part of the fingerprint of an Options.CacheFile and is bumped whenever a cacheEntry changes
+	cacheVersion int = 3
+
+	// entropyMinLength is the shortest quoted string that Options.Redact measures the entropy of
+	entropyMinLength int = 24
+
+	// entropyThreshold is the Shannon entropy in bits per byte at which a quoted string is redacted as a secret
+	entropyThreshold float64 = 4.2
+)
@@ -0,0 +1,192 @@
+package summarize
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Compare returns a Change for every file that was added, removed or modified from the older to the newer Document,
+// sorted by path. Files are matched by their path relative to the workspace of their Document, so summaries of the
+// same project that was moved can still be compared.
+func Compare(older, newer *Document) []Change {
+	before, after := older.relative(), newer.relative()
+	var changes []Change
+	for path, in := range after {
+		was, ok := before[path]
+		switch {
+		case !ok:
+			changes = append(changes, change(path, ChangeAdded, "", in.Contents))
+		case was.Contents != in.Contents:
+			changes = append(changes, change(path, ChangeModified, was.Contents, in.Contents))
+		}
+	}
+	for path, was := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, change(path, ChangeRemoved, was.Contents, ""))
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes
+}
+
+// relative returns the Results of the Document by their path relative to its Workspace
+func (d *Document) relative() map[string]Result {
+	out := make(map[string]Result, len(d.Results))
+	for _, in := range d.Results {
+		out[d.relativePath(in.Path)] = in
+	}
+	return out
+}
+
+// change returns the Change of the path with the unified diff from the contents it was to the contents it is
+func change(path, status, was, is string) Change {
+	from, to := "a/"+path, "b/"+path
+	if status == ChangeAdded {
+		from = "/dev/null"
+	}
+	if status == ChangeRemoved {
+		to = "/dev/null"
+	}
+	edits := diffLines(splitLines(was), splitLines(is))
+	c := Change{Path: path, Status: status, Diff: unified(from, to, edits, diffContext)}
+	for _, e := range edits {
+		switch e.op {
+		case '+':
+			c.Added++
+		case '-':
+			c.Removed++
+		}
+	}
+	return c
+}
+
+// splitLines splits s after every newline, so that a missing newline at the end of s is a difference of its own
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script from a to b, as found by the O(ND) algorithm of Eugene W. Myers
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace keeps the furthest x of every diagonal k from -d to d after each step d to walk the path back from the end
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+				return backtrack(a, b, trace)
+			}
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+	}
+	return nil
+}
+
+// backtrack walks the trace of diffLines back from the end of a and b into the edits that lead there
+func backtrack(a, b []string, trace [][]int) []edit {
+	var edits []edit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			edits = append(edits, edit{op: ' ', line: a[x]})
+		}
+		if x == prevX {
+			edits = append(edits, edit{op: '+', line: b[prevY]})
+		} else {
+			edits = append(edits, edit{op: '-', line: a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		edits = append(edits, edit{op: ' ', line: a[x]})
+	}
+	slices.Reverse(edits)
+	return edits
+}
+
+// unified renders the edits from the file named from to the file named to as a unified diff with context lines of
+// unchanged text around each hunk, or an empty string when nothing changed
+func unified(from, to string, edits []edit, context int) string {
+	// positions holds the line of a and b that each edit starts at
+	positions := make([][2]int, len(edits)+1)
+	for i, e := range edits {
+		positions[i+1] = positions[i]
+		if e.op != '+' {
+			positions[i+1][0]++
+		}
+		if e.op != '-' {
+			positions[i+1][1]++
+		}
+	}
+	var sb strings.Builder
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", from, to))
+		}
+		start, last := max(0, i-context), i
+		for j := i + 1; j < len(edits) && j <= last+2*context; j++ {
+			if edits[j].op != ' ' {
+				last = j
+			}
+		}
+		stop := min(len(edits), last+context+1)
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(positions[start][0], positions[stop][0]-positions[start][0]),
+			hunkRange(positions[start][1], positions[stop][1]-positions[start][1])))
+		for _, e := range edits[start:stop] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return sb.String()
+}
+
+// hunkRange renders the start and count of the lines of a hunk that starts after the line at the 0 based start
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,35 @@
+package summarize
+
+import "strings"
+
+// dotted reports whether the Options.Dots policy leaves out the slash separated rel path, which is a directory when
+// isDir is set. A path inside of an Options.DotAllow path is kept, and so is a directory on the way to one, so that the
+// walk can reach it.
+func (r *run) dotted(rel string, isDir bool) bool {
+	if len(rel) == 0 || r.opts.Dots == DotsInclude {
+		return false
+	}
+	dirs := strings.Split(rel, "/")
+	name := ""
+	if !isDir {
+		dirs, name = dirs[:len(dirs)-1], dirs[len(dirs)-1]
+	}
+	dotted := false
+	if r.opts.Dots == DotsExcludeFiles || r.opts.Dots == DotsExclude {
+		dotted = strings.HasPrefix(name, ".")
+	}
+	if r.opts.Dots == DotsExcludeDirs || r.opts.Dots == DotsExclude {
+		for _, dir := range dirs {
+			dotted = dotted || strings.HasPrefix(dir, ".")
+		}
+	}
+	if !dotted {
+		return false
+	}
+	for _, allow := range r.opts.DotAllow {
+		if rel == allow || strings.HasPrefix(rel, allow+"/") || (isDir && strings.HasPrefix(allow, rel+"/")) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package summarize
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDotted(t *testing.T) {
+	tests := []struct {
+		rel   string
+		isDir bool
+		// whether each of DotsInclude, DotsExcludeFiles, DotsExcludeDirs and DotsExclude leaves the path out
+		dotted [4]bool
+	}{
+		{"main.go", false, [4]bool{false, false, false, false}},
+		{".env", false, [4]bool{false, true, false, true}},
+		{"cmd/.env", false, [4]bool{false, true, false, true}},
+		{".github", true, [4]bool{false, false, true, true}},
+		{".github/ci.yml", false, [4]bool{false, false, true, true}},
+		{"pkg/.cache", true, [4]bool{false, false, true, true}},
+		{".github/.env", false, [4]bool{false, true, true, true}},
+		{"pkg", true, [4]bool{false, false, false, false}},
+		{"", true, [4]bool{false, false, false, false}},
+	}
+	policies := []string{DotsInclude, DotsExcludeFiles, DotsExcludeDirs, DotsExclude}
+	for _, tt := range tests {
+		for i, dots := range policies {
+			r := &run{opts: Options{Dots: dots}}
+			if got := r.dotted(tt.rel, tt.isDir); got != tt.dotted[i] {
+				t.Errorf("dotted(%q, %v) with -dots=%s = %v, want %v", tt.rel, tt.isDir, dots, got, tt.dotted[i])
+			}
+		}
+	}
+}
+
+func TestRunDotAllow(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"main.go":                      "package main\n",
+		".env":                         "KEY=value\n",
+		".github/ci.yml":               "on: push\n",
+		".github/workflows/build.yml":  "on: push\n",
+		".github/workflows/.cache.yml": "on: push\n",
+		".config/settings.yml":         "key: value\n",
+	})
+	tests := []struct {
+		dots  string
+		allow []string
+		want  []string
+	}{
+		{DotsInclude, nil, []string{".config/settings.yml", ".env", ".github/ci.yml", ".github/workflows/.cache.yml", ".github/workflows/build.yml", "main.go"}},
+		{DotsExcludeFiles, nil, []string{".config/settings.yml", ".github/ci.yml", ".github/workflows/build.yml", "main.go"}},
+		{DotsExcludeDirs, nil, []string{".env", "main.go"}},
+		{DotsExclude, nil, []string{"main.go"}},
+		// the nested path is reached through the dot-directory above it, which is walked but not summarized
+		{DotsExclude, []string{".github/workflows"}, []string{".github/workflows/.cache.yml", ".github/workflows/build.yml", "main.go"}},
+		{DotsExclude, []string{".github/workflows/build.yml", ".env"}, []string{".env", ".github/workflows/build.yml", "main.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dots, func(t *testing.T) {
+			opts := treeOptions(dir)
+			opts.IncludeExt, opts.Dots, opts.DotAllow = []string{"go", "yml", "env"}, tt.dots, tt.allow
+			s, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			summary, err := s.Run(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, result := range summary.Results {
+				got = append(got, result.Path)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("-dots=%s with %v summarized %v, want %v", tt.dots, tt.allow, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package summarize
+
+import "fmt"
+
+// Error describes the Kind of step that failed, the Path it failed on and the error that caused it
+func (e *Error) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("%s error: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s error in %s: %v", e.Kind, e.Path, e.Err)
+}
+
+// Unwrap returns the error that caused the Error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
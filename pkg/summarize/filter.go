@@ -0,0 +1,156 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// compileFilters compiles the Options.IncludeGlob, the Options.ExcludeGlob as the rules of an ignore file at the root
+// of the SourceDir and the Options.ExcludeRegex
+func compileFilters(opts Options) (f filters, err error) {
+	if f.includeGlob, err = compileGlobs("include", opts.IncludeGlob); err != nil {
+		return filters{}, err
+	}
+	if len(opts.ExcludeGlob) > 0 {
+		if f.excludeGlob, err = parseIgnore("exclude glob", "", []byte(strings.Join(opts.ExcludeGlob, "\n"))); err != nil {
+			return filters{}, err
+		}
+	}
+	for _, expr := range opts.ExcludeRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return filters{}, fmt.Errorf("invalid exclude regex %q: %w", expr, err)
+		}
+		f.excludeRegex = append(f.excludeRegex, re)
+	}
+	return f, nil
+}
+
+// decide returns the Explanation of the rule that includes or excludes the path at the slash separated rel. The skip
+// list, the dots policy, the ignore files, the exclude regular expressions and the exclude globs are checked in that
+// order on directories and files, where a directory that is not included is pruned, and then the include globs and
+// the exclude and include types are checked on files. The error is returned when the shebang of a file cannot be read.
+func (r *run) decide(path, rel string, d fs.DirEntry) (Explanation, error) {
+	e := Explanation{Path: r.aliased(rel)}
+	isDir := d.IsDir()
+	skipPath, matchRel := path, rel
+	if isDir {
+		// a trailing separator lets "node_modules/" or "^vendor/" match the directory itself
+		skipPath, matchRel = path+string(filepath.Separator), rel+"/"
+	}
+	if avoidThis, skip := r.skipped(skipPath); skip {
+		return e.excluded(RuleSkip, avoidThis), nil
+	}
+	if r.dotted(rel, isDir) {
+		return e.excluded(RuleDots, r.opts.Dots), nil
+	}
+	if ignored, reason := r.ignore.ignored(rel, isDir); ignored {
+		return e.excluded(RuleIgnoreFile, reason), nil
+	}
+	if len(rel) > 0 {
+		for _, re := range r.excludeRegex {
+			if re.MatchString(matchRel) {
+				return e.excluded(RuleExcludeRegex, re.String()), nil
+			}
+		}
+		if r.excludeGlob != nil {
+			if rule := r.excludeGlob.match(rel, isDir); rule != nil && !rule.negate {
+				return e.excluded(RuleExcludeGlob, rule.pattern), nil
+			}
+		}
+	}
+	if isDir {
+		e.Included = true
+		return e, nil
+	}
+	if len(r.includeGlob) > 0 {
+		i := slices.IndexFunc(r.includeGlob, func(re *regexp.Regexp) bool { return re.MatchString(rel) })
+		if i < 0 {
+			return e.excluded(RuleIncludeGlob, ""), nil
+		}
+		e.Rule, e.Match = RuleIncludeGlob, r.opts.IncludeGlob[i]
+	}
+
+	// classify the file once by its extension, special name or shebang, without opening a pipe or device that blocks
+	e.Type = Classify(path, nil)
+	if len(e.Type) == 0 && (d.Type().IsRegular() || d.Type()&fs.ModeSymlink != 0) {
+		var err error
+		if e.Type, err = ClassifyFile(path); err != nil {
+			return e, err
+		}
+	}
+	for _, excludeThis := range r.opts.ExcludeExt {
+		if strings.EqualFold(excludeThis, e.Type) {
+			return e.excluded(RuleExcludeExt, excludeThis), nil
+		}
+	}
+	i := slices.IndexFunc(r.opts.IncludeExt, func(ext string) bool { return strings.EqualFold(ext, e.Type) })
+	if i < 0 {
+		return e.excluded(RuleIncludeExt, ""), nil
+	}
+	if len(e.Rule) == 0 {
+		e.Rule, e.Match = RuleIncludeExt, r.opts.IncludeExt[i]
+	}
+	e.Included = true
+	return e, nil
+}
+
+// excluded returns e excluded by the rule that matched
+func (e Explanation) excluded(rule, match string) Explanation {
+	e.Included, e.Rule, e.Match = false, rule, match
+	return e
+}
+
+// Explain returns the Explanation of the rule that includes or excludes the path the way that Run walks to it. The
+// path is relative to the SourceDir, or starts with the Alias of its Root when the Options.Roots are aliased, unless
+// it is absolute. The directories above the path are decided first, so a path inside a pruned directory is explained
+// by the rule of that Explanation.Dir. The files selected by Options.Git pass through the same rules.
+func (s *Summarizer) Explain(path string) (Explanation, error) {
+	root, rel, err := s.locate(path)
+	if err != nil {
+		return Explanation{}, &Error{Kind: KindConfig, Path: path, Err: err}
+	}
+	r := &run{
+		ctx:     context.Background(),
+		opts:    s.opts,
+		ignore:  newIgnorer(root.Dir),
+		filters: s.filters,
+		errs:    &collector{},
+		root:    root,
+	}
+	dirs := []string{""}
+	if len(rel) > 0 {
+		parts := strings.Split(rel, "/")
+		for i := 1; i < len(parts); i++ {
+			dirs = append(dirs, strings.Join(parts[:i], "/"))
+		}
+		dirs = append(dirs, rel)
+	}
+	for _, dir := range dirs {
+		path = filepath.Join(root.Dir, filepath.FromSlash(dir))
+		info, err := os.Lstat(path)
+		if err != nil {
+			return Explanation{}, &Error{Kind: KindRead, Path: path, Err: err}
+		}
+		e, err := r.decide(path, dir, fs.FileInfoToDirEntry(info))
+		if err != nil {
+			return Explanation{}, &Error{Kind: KindRead, Path: path, Err: err}
+		}
+		if !e.Included && dir != rel {
+			e.Path, e.Dir = r.aliased(rel), r.aliased(dir)
+		}
+		if !e.Included || dir == rel {
+			return e, nil
+		}
+		if err := r.loadIgnores(dir); err != nil {
+			return Explanation{}, &Error{Kind: KindRead, Path: path, Err: err}
+		}
+	}
+	return Explanation{}, nil
+}
@@ -0,0 +1,68 @@
+package summarize
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":           "pkg/gen.go\n",
+		"cmd/app/main.go":      "package main\n",
+		"cmd/app/main_test.go": "package main\n",
+		"internal/x/x_test.go": "package x\n",
+		"internal/legacy/l.go": "package legacy\n",
+		"pkg/y.go":             "package y\n",
+		"pkg/y_test.go":        "package y\n",
+		"pkg/gen.go":           "package y\n",
+		"vendor/v/v.go":        "package v\n",
+		"node_modules/m/m.js":  "module.exports = {}\n",
+		"docs/notes.txt":       "not included\n",
+		"scripts/release":      "#!/bin/sh\n",
+	}
+	writeTree(t, dir, files)
+	opts := treeOptions(dir, "node_modules/")
+	opts.IncludeExt, opts.ExcludeExt = []string{"go", "js", "sh"}, []string{"sh"}
+	opts.GitIgnore = true
+	opts.IncludeGlob = []string{"cmd/**", "internal/**", "pkg/**", "vendor/**", "docs/**", "scripts/**"}
+	opts.ExcludeGlob = []string{"*_test.go", "!internal/**", "internal/legacy/**"}
+	opts.ExcludeRegex = []string{"^vendor/"}
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path     string
+		included bool
+		rule     string
+		match    string
+		dir      string
+	}{
+		{"cmd/app/main.go", true, RuleIncludeGlob, "cmd/**", ""},
+		{"cmd/app/main_test.go", false, RuleExcludeGlob, "*_test.go", ""},
+		{"internal/x/x_test.go", true, RuleIncludeGlob, "internal/**", ""},
+		{"internal/legacy/l.go", false, RuleExcludeGlob, "internal/legacy/**", ""},
+		{"pkg/gen.go", false, RuleIgnoreFile, ".gitignore:1 pkg/gen.go", ""},
+		{"vendor/v/v.go", false, RuleExcludeRegex, "^vendor/", "vendor"},
+		{"node_modules/m/m.js", false, RuleSkip, "node_modules/", "node_modules"},
+		{"docs/notes.txt", false, RuleIncludeExt, "", ""},
+		{"scripts/release", false, RuleExcludeExt, "sh", ""},
+		{".gitignore", false, RuleIncludeGlob, "", ""},
+		{"pkg", true, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			e, err := s.Explain(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Path != tt.path || e.Included != tt.included || e.Rule != tt.rule || e.Match != tt.match || e.Dir != tt.dir {
+				t.Errorf("Explain(%q) = %+v, want included %v by %q %q in %q", tt.path, e, tt.included, tt.rule, tt.match, tt.dir)
+			}
+		})
+	}
+	if _, err := s.Explain(filepath.Dir(dir)); err == nil {
+		t.Error("Explain of a path outside of the source directory returned no error")
+	}
+}
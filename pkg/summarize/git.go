@@ -0,0 +1,450 @@
+package summarize
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// openGitRepo finds the worktree that contains dir by walking up to the first .git directory or .git file
+func openGitRepo(dir string) (*gitRepo, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for current := abs; ; current = filepath.Dir(current) {
+		dotGit := filepath.Join(current, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			g := &gitRepo{worktree: current, gitDir: dotGit}
+			if !info.IsDir() {
+				// worktrees and submodules use a .git file that points at the real git directory
+				contents, err := os.ReadFile(dotGit)
+				if err != nil {
+					return nil, err
+				}
+				gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(contents)), "gitdir:")
+				if !ok {
+					return nil, fmt.Errorf("%s does not point to a git directory", dotGit)
+				}
+				g.gitDir = strings.TrimSpace(gitDir)
+				if !filepath.IsAbs(g.gitDir) {
+					g.gitDir = filepath.Join(current, g.gitDir)
+				}
+			}
+			g.commonDir = g.gitDir
+			if common, err := os.ReadFile(filepath.Join(g.gitDir, "commondir")); err == nil {
+				g.commonDir = strings.TrimSpace(string(common))
+				if !filepath.IsAbs(g.commonDir) {
+					g.commonDir = filepath.Join(g.gitDir, g.commonDir)
+				}
+			}
+			if format, err := os.ReadFile(filepath.Join(g.commonDir, "config")); err == nil &&
+				bytes.Contains(bytes.ToLower(format), []byte("objectformat = sha256")) {
+				return nil, fmt.Errorf("%s uses sha256 object names which are not supported", g.commonDir)
+			}
+			return g, nil
+		}
+		if filepath.Dir(current) == current {
+			return nil, fmt.Errorf("%s is not inside a git repository", abs)
+		}
+	}
+}
+
+// resolve turns a revision such as HEAD, a branch, a tag, a remote branch or an object name, optionally followed by
+// any number of ~N and ^N suffixes, into the name of a commit
+func (g *gitRepo) resolve(rev string) (gitHash, error) {
+	base, suffix := rev, ""
+	if i := strings.IndexAny(rev, "~^"); i > 0 {
+		base, suffix = rev[:i], rev[i:]
+	}
+	h, err := g.resolveName(base)
+	if err != nil {
+		return h, err
+	}
+	if h, err = g.peel(h); err != nil {
+		return h, err
+	}
+	for len(suffix) > 0 {
+		op := suffix[0]
+		suffix = suffix[1:]
+		digits := len(suffix) - len(strings.TrimLeft(suffix, "0123456789"))
+		n := 1
+		if digits > 0 {
+			if n, err = strconv.Atoi(suffix[:digits]); err != nil {
+				return h, fmt.Errorf("invalid revision %q: %w", rev, err)
+			}
+			suffix = suffix[digits:]
+		}
+		if op == '^' {
+			if n == 0 {
+				continue
+			}
+			c, err := g.readCommit(h)
+			if err != nil {
+				return h, err
+			}
+			if n > len(c.parents) {
+				return h, fmt.Errorf("revision %q has no parent %d", rev, n)
+			}
+			h = c.parents[n-1]
+			continue
+		}
+		for i := 0; i < n; i++ {
+			c, err := g.readCommit(h)
+			if err != nil {
+				return h, err
+			}
+			if len(c.parents) == 0 {
+				return h, fmt.Errorf("revision %q goes past the first commit", rev)
+			}
+			h = c.parents[0]
+		}
+	}
+	return h, nil
+}
+
+// resolveName looks up a full object name, a symbolic ref, a loose ref, a packed ref or an abbreviated object name
+func (g *gitRepo) resolveName(name string) (gitHash, error) {
+	if h, err := parseGitHash(name); err == nil {
+		return h, nil
+	}
+	candidates := []string{"refs/" + name, "refs/tags/" + name, "refs/heads/" + name, "refs/remotes/" + name, "refs/remotes/" + name + "/HEAD"}
+	if strings.HasPrefix(name, "refs/") || strings.Trim(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") == "" {
+		candidates = append([]string{name}, candidates...) // HEAD, ORIG_HEAD, FETCH_HEAD or a full ref
+	}
+	for _, ref := range candidates {
+		h, err := g.readRef(ref, 0)
+		if err == nil {
+			return h, nil
+		}
+		if !os.IsNotExist(err) {
+			return h, err
+		}
+	}
+	if h, err := g.abbreviated(name); err == nil {
+		return h, nil
+	}
+	return gitHash{}, fmt.Errorf("unknown revision %q", name)
+}
+
+// readRef reads a loose ref from the gitDir or commonDir, falling back to packed-refs, and follows symbolic refs
+func (g *gitRepo) readRef(ref string, depth int) (gitHash, error) {
+	if depth > 8 {
+		return gitHash{}, fmt.Errorf("symbolic ref %s is nested too deeply", ref)
+	}
+	for _, dir := range []string{g.gitDir, g.commonDir} {
+		contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(contents))
+		if target, ok := strings.CutPrefix(value, "ref:"); ok {
+			return g.readRef(strings.TrimSpace(target), depth+1)
+		}
+		if fields := strings.Fields(value); len(fields) > 0 {
+			value = fields[0] // FETCH_HEAD lists the fetched branch after the object name
+		}
+		return parseGitHash(value)
+	}
+	f, err := os.Open(filepath.Join(g.commonDir, "packed-refs"))
+	if err != nil {
+		return gitHash{}, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, packedRef, ok := strings.Cut(scanner.Text(), " ")
+		if ok && packedRef == ref {
+			return parseGitHash(name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return gitHash{}, err
+	}
+	return gitHash{}, os.ErrNotExist
+}
+
+// abbreviated finds the single object whose name starts with the hex prefix in the loose objects or the packs
+func (g *gitRepo) abbreviated(prefix string) (gitHash, error) {
+	prefix = strings.ToLower(prefix)
+	if len(prefix) < 4 || len(prefix) > 40 || strings.Trim(prefix, "0123456789abcdef") != "" {
+		return gitHash{}, fmt.Errorf("%q is not an abbreviated object name", prefix)
+	}
+	var found []gitHash
+	entries, _ := os.ReadDir(filepath.Join(g.commonDir, "objects", prefix[:2]))
+	for _, entry := range entries {
+		if strings.HasPrefix(prefix[:2]+entry.Name(), prefix) {
+			if h, err := parseGitHash(prefix[:2] + entry.Name()); err == nil {
+				found = append(found, h)
+			}
+		}
+	}
+	if err := g.loadPacks(); err != nil {
+		return gitHash{}, err
+	}
+	for _, p := range g.packs {
+		for _, h := range p.names {
+			if strings.HasPrefix(h.String(), prefix) && !slices.Contains(found, h) {
+				found = append(found, h)
+			}
+		}
+	}
+	if len(found) != 1 {
+		return gitHash{}, fmt.Errorf("%q matches %d objects", prefix, len(found))
+	}
+	return found[0], nil
+}
+
+// peel follows annotated tags until h names a commit
+func (g *gitRepo) peel(h gitHash) (gitHash, error) {
+	for i := 0; i < 8; i++ {
+		kind, data, err := g.readObject(h)
+		if err != nil {
+			return h, err
+		}
+		if kind != "tag" {
+			return h, nil
+		}
+		object, _, _ := strings.Cut(strings.TrimPrefix(string(data), "object "), "\n")
+		if h, err = parseGitHash(object); err != nil {
+			return h, err
+		}
+	}
+	return h, errors.New("tag is nested too deeply")
+}
+
+// readCommit parses the tree, parents and committer time of the commit h
+func (g *gitRepo) readCommit(h gitHash) (*gitCommit, error) {
+	kind, data, err := g.readObject(h)
+	if err != nil {
+		return nil, err
+	}
+	if kind != "commit" {
+		return nil, fmt.Errorf("%s is a %s, not a commit", h, kind)
+	}
+	c := &gitCommit{hash: h}
+	headers, _, _ := bytes.Cut(data, []byte("\n\n"))
+	for _, line := range strings.Split(string(headers), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			if c.tree, err = parseGitHash(value); err != nil {
+				return nil, err
+			}
+		case "parent":
+			parent, err := parseGitHash(value)
+			if err != nil {
+				return nil, err
+			}
+			c.parents = append(c.parents, parent)
+		case "committer":
+			fields := strings.Fields(value)
+			if len(fields) >= 2 {
+				c.time, _ = strconv.ParseInt(fields[len(fields)-2], 10, 64)
+			}
+		}
+	}
+	return c, nil
+}
+
+// readTree flattens the tree h into the blob name of every file path below prefix, skipping submodules
+func (g *gitRepo) readTree(h gitHash, prefix string, files map[string]gitHash) error {
+	kind, data, err := g.readObject(h)
+	if err != nil {
+		return err
+	}
+	if kind != "tree" {
+		return fmt.Errorf("%s is a %s, not a tree", h, kind)
+	}
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return fmt.Errorf("tree %s is malformed", h)
+		}
+		mode, name := string(data[:space]), string(data[space+1:nul])
+		var entry gitHash
+		copy(entry[:], data[nul+1:nul+21])
+		data = data[nul+21:]
+		switch mode {
+		case "40000":
+			if err := g.readTree(entry, path.Join(prefix, name), files); err != nil {
+				return err
+			}
+		case "160000":
+			continue // submodule commits are not files in this repository
+		default:
+			files[path.Join(prefix, name)] = entry
+		}
+	}
+	return nil
+}
+
+// commitFiles returns the blob name of every file in the tree of the commit h
+func (g *gitRepo) commitFiles(h gitHash) (map[string]gitHash, error) {
+	c, err := g.readCommit(h)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]gitHash)
+	return files, g.readTree(c.tree, "", files)
+}
+
+// mergeBase returns the best common ancestor of a and b by painting both histories newest commit first until only
+// commits reachable from an already found common ancestor remain
+func (g *gitRepo) mergeBase(a, b gitHash) (gitHash, error) {
+	const (
+		fromA = 1 << iota
+		fromB
+		stale
+	)
+	if a == b {
+		return a, nil
+	}
+	flags := make(map[gitHash]int)
+	queue := &gitCommitQueue{}
+	push := func(h gitHash, flag int) error {
+		if flags[h]&flag == flag {
+			return nil
+		}
+		flags[h] |= flag
+		c, err := g.readCommit(h)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, c)
+		return nil
+	}
+	if err := push(a, fromA); err != nil {
+		return gitHash{}, err
+	}
+	if err := push(b, fromB); err != nil {
+		return gitHash{}, err
+	}
+	var found []gitHash
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(*gitCommit)
+		flag := flags[c.hash] & (fromA | fromB | stale)
+		if flag&(fromA|fromB) == fromA|fromB && flag&stale == 0 {
+			found = append(found, c.hash)
+			flag |= stale
+			flags[c.hash] = flag
+		}
+		for _, parent := range c.parents {
+			if err := push(parent, flag); err != nil {
+				return gitHash{}, err
+			}
+		}
+		if queue.onlyStale(flags, stale) {
+			break
+		}
+	}
+	if len(found) == 0 {
+		return gitHash{}, fmt.Errorf("%s and %s have no common ancestor", a, b)
+	}
+	return found[0], nil
+}
+
+// Len implements heap.Interface
+func (q gitCommitQueue) Len() int { return len(q) }
+
+// Less implements heap.Interface with the newest committer time first
+func (q gitCommitQueue) Less(i, j int) bool { return q[i].time > q[j].time }
+
+// Swap implements heap.Interface
+func (q gitCommitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// Push implements heap.Interface
+func (q *gitCommitQueue) Push(x any) { *q = append(*q, x.(*gitCommit)) }
+
+// Pop implements heap.Interface
+func (q *gitCommitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// onlyStale reports whether every queued commit is already reachable from a found common ancestor
+func (q gitCommitQueue) onlyStale(flags map[gitHash]int, stale int) bool {
+	for _, c := range q {
+		if flags[c.hash]&stale == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// gitPaths returns the worktree paths (relative to the repository root, slash separated and sorted) selected by the
+// mode: every tracked file, the files staged in the index, or the files that changed since the rev
+func (g *gitRepo) gitPaths(mode, rev string, mergeBase bool) ([]string, error) {
+	entries, err := g.readGitIndex()
+	if err != nil {
+		return nil, fmt.Errorf("reading git index: %w", err)
+	}
+	var paths []string
+	switch mode {
+	case GitModeTracked:
+		for _, e := range entries {
+			paths = append(paths, e.path)
+		}
+	case GitModeStaged:
+		head := map[string]gitHash{}
+		if h, err := g.resolve("HEAD"); err == nil {
+			if head, err = g.commitFiles(h); err != nil {
+				return nil, err
+			}
+		}
+		for _, e := range entries {
+			if committed, ok := head[e.path]; !ok || committed != e.hash {
+				paths = append(paths, e.path)
+			}
+		}
+	case GitModeChanged:
+		since, err := g.resolve(rev)
+		if err != nil {
+			return nil, err
+		}
+		if mergeBase {
+			head, err := g.resolve("HEAD")
+			if err != nil {
+				return nil, err
+			}
+			if since, err = g.mergeBase(since, head); err != nil {
+				return nil, err
+			}
+		}
+		base, err := g.commitFiles(since)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if committed, ok := base[e.path]; !ok || committed != e.hash {
+				paths = append(paths, e.path)
+				continue
+			}
+			modified, err := e.modified(filepath.Join(g.worktree, filepath.FromSlash(e.path)))
+			if err != nil {
+				return nil, err
+			}
+			if modified {
+				paths = append(paths, e.path)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown git mode %q", mode)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
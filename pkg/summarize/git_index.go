@@ -0,0 +1,136 @@
+package summarize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// readGitIndex parses the entries of the version 2, 3 or 4 index file in the gitDir. Only the stage 0 entries of
+// regular files and symlinks are returned.
+func (g *gitRepo) readGitIndex() ([]gitIndexEntry, error) {
+	raw, err := os.ReadFile(filepath.Join(g.gitDir, "index"))
+	if os.IsNotExist(err) {
+		return nil, nil // a repository without any staged files
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 12 || !bytes.Equal(raw[:4], []byte("DIRC")) {
+		return nil, errors.New("index has an invalid signature")
+	}
+	version := binary.BigEndian.Uint32(raw[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("index version %d is not supported", version)
+	}
+	count := int(binary.BigEndian.Uint32(raw[8:12]))
+	entries := make([]gitIndexEntry, 0, count)
+	at := 12
+	previous := ""
+	for i := 0; i < count; i++ {
+		start := at
+		if len(raw) < at+62 {
+			return nil, errors.New("index is truncated")
+		}
+		e := gitIndexEntry{
+			mtimeSec:  binary.BigEndian.Uint32(raw[at+8:]),
+			mtimeNsec: binary.BigEndian.Uint32(raw[at+12:]),
+			mode:      binary.BigEndian.Uint32(raw[at+24:]),
+			size:      binary.BigEndian.Uint32(raw[at+36:]),
+		}
+		copy(e.hash[:], raw[at+40:at+60])
+		flags := binary.BigEndian.Uint16(raw[at+60:])
+		e.stage = int(flags>>12) & 3
+		at += 62
+		if version >= 3 && flags&0x4000 != 0 {
+			at += 2 // extended flags
+		}
+		if version == 4 {
+			strip, n := decodeGitVarint(raw[at:])
+			if n <= 0 || strip > len(previous) {
+				return nil, errors.New("index has an invalid path prefix")
+			}
+			at += n
+			nul := bytes.IndexByte(raw[at:], 0)
+			if nul < 0 {
+				return nil, errors.New("index is truncated")
+			}
+			e.path = previous[:len(previous)-strip] + string(raw[at:at+nul])
+			at += nul + 1
+		} else {
+			nul := bytes.IndexByte(raw[at:], 0)
+			if nul < 0 {
+				return nil, errors.New("index is truncated")
+			}
+			e.path = string(raw[at : at+nul])
+			at = start + ((at + nul - start + 8) &^ 7) // entries are padded with 1 to 8 NUL bytes
+		}
+		previous = e.path
+		if e.stage != 0 || !gitIndexFileMode(e.mode) {
+			continue // skip conflicts, submodules and sparse directory entries
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// decodeGitVarint decodes the big endian varint used by index paths and offset deltas where every continuation adds
+// one, returning the value and the number of bytes read or 0 when b is truncated
+func decodeGitVarint(b []byte) (int, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	c := b[0]
+	value := int(c & 0x7f)
+	n := 1
+	for c&0x80 != 0 {
+		if n >= len(b) {
+			return 0, 0
+		}
+		c = b[n]
+		n++
+		value = ((value + 1) << 7) | int(c&0x7f)
+	}
+	return value, n
+}
+
+// gitIndexFileMode reports whether the mode of an index entry is a regular file or a symlink
+func gitIndexFileMode(mode uint32) bool {
+	switch mode >> 12 {
+	case 0b1000, 0b1010:
+		return true
+	default:
+		return false
+	}
+}
+
+// modified reports whether the file at path in the worktree differs from the blob recorded in the index entry e. The
+// file is only hashed when its size or modification time no longer match the index.
+func (e gitIndexEntry) modified(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	mtime := info.ModTime()
+	if uint32(info.Size()) == e.size && uint32(mtime.Unix()) == e.mtimeSec && uint32(mtime.Nanosecond()) == e.mtimeNsec {
+		return false, nil
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false, err
+		}
+		return hashGitBlob([]byte(filepath.ToSlash(target))) != e.hash, nil
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return hashGitBlob(contents) != e.hash, nil
+}
@@ -0,0 +1,363 @@
+package summarize
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	gitObjCommit   = 1
+	gitObjTree     = 2
+	gitObjBlob     = 3
+	gitObjTag      = 4
+	gitObjOfsDelta = 6
+	gitObjRefDelta = 7
+)
+
+// errGitObjectNotFound is returned when a hash is neither a loose object nor inside a pack
+var errGitObjectNotFound = errors.New("git object not found")
+
+// gitObjectKinds maps the pack object types onto the names used by loose objects
+var gitObjectKinds = map[int]string{
+	gitObjCommit: "commit",
+	gitObjTree:   "tree",
+	gitObjBlob:   "blob",
+	gitObjTag:    "tag",
+}
+
+// parseGitHash decodes a full 40 character hex object name
+func parseGitHash(s string) (gitHash, error) {
+	var h gitHash
+	if len(s) != 2*len(h) {
+		return h, fmt.Errorf("invalid object name %q", s)
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("invalid object name %q: %w", s, err)
+	}
+	return h, nil
+}
+
+// String implements the Stringer interface
+func (h gitHash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// hashGitBlob returns the object name git would assign to contents when it is stored as a blob
+func hashGitBlob(contents []byte) gitHash {
+	sum := sha1.New()
+	_, _ = fmt.Fprintf(sum, "blob %d\x00", len(contents))
+	_, _ = sum.Write(contents)
+	var h gitHash
+	copy(h[:], sum.Sum(nil))
+	return h
+}
+
+// readObject returns the kind and the inflated contents of the object named h from the loose objects or the packs
+func (g *gitRepo) readObject(h gitHash) (string, []byte, error) {
+	kind, data, err := g.readLooseObject(h)
+	if err == nil || !errors.Is(err, errGitObjectNotFound) {
+		return kind, data, err
+	}
+	if err := g.loadPacks(); err != nil {
+		return "", nil, err
+	}
+	for _, p := range g.packs {
+		offset, ok := p.find(h)
+		if !ok {
+			continue
+		}
+		objType, data, err := g.readPackObject(p, offset)
+		if err != nil {
+			return "", nil, fmt.Errorf("reading %s from %s: %w", h, p.path, err)
+		}
+		return gitObjectKinds[objType], data, nil
+	}
+	return "", nil, fmt.Errorf("%w: %s", errGitObjectNotFound, h)
+}
+
+// readLooseObject inflates objects/xx/yyyy... and splits the "kind size\x00" header from the contents
+func (g *gitRepo) readLooseObject(h gitHash) (string, []byte, error) {
+	name := h.String()
+	f, err := os.Open(filepath.Join(g.commonDir, "objects", name[:2], name[2:]))
+	if os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("%w: %s", errGitObjectNotFound, name)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("inflating object %s: %w", name, err)
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, fmt.Errorf("inflating object %s: %w", name, err)
+	}
+	nul := bytes.IndexByte(raw, 0)
+	if nul < 0 {
+		return "", nil, fmt.Errorf("object %s has no header", name)
+	}
+	kind, size, ok := strings.Cut(string(raw[:nul]), " ")
+	if !ok {
+		return "", nil, fmt.Errorf("object %s has a malformed header", name)
+	}
+	data := raw[nul+1:]
+	if n, err := strconv.Atoi(size); err != nil || n != len(data) {
+		return "", nil, fmt.Errorf("object %s has a size mismatch", name)
+	}
+	return kind, data, nil
+}
+
+// loadPacks reads the version 2 .idx of every pack in objects/pack once
+func (g *gitRepo) loadPacks() error {
+	if g.packsLoaded {
+		return nil
+	}
+	g.packsLoaded = true
+	matches, err := filepath.Glob(filepath.Join(g.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idxPath := range matches {
+		p, err := openGitPack(idxPath)
+		if err != nil {
+			return err
+		}
+		g.packs = append(g.packs, p)
+	}
+	return nil
+}
+
+// openGitPack parses the fanout, names and offsets of a version 2 pack index
+func openGitPack(idxPath string) (*gitPack, error) {
+	idx, err := os.ReadFile(idxPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(idx) < 8+256*4 || !bytes.Equal(idx[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, fmt.Errorf("%s is not a version 2 pack index", idxPath)
+	}
+	fanout := idx[8 : 8+256*4]
+	count := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	namesAt := 8 + 256*4
+	crcAt := namesAt + count*20
+	offsetsAt := crcAt + count*4
+	largeAt := offsetsAt + count*4
+	if len(idx) < largeAt {
+		return nil, fmt.Errorf("%s is truncated", idxPath)
+	}
+	p := &gitPack{
+		path:    strings.TrimSuffix(idxPath, ".idx") + ".pack",
+		names:   make([]gitHash, count),
+		offsets: make([]int64, count),
+	}
+	for i := 0; i < 256; i++ {
+		p.fanout[i] = binary.BigEndian.Uint32(fanout[i*4:])
+	}
+	for i := 0; i < count; i++ {
+		copy(p.names[i][:], idx[namesAt+i*20:])
+		offset := binary.BigEndian.Uint32(idx[offsetsAt+i*4:])
+		if offset&0x80000000 != 0 {
+			at := largeAt + int(offset&0x7fffffff)*8
+			if len(idx) < at+8 {
+				return nil, fmt.Errorf("%s is truncated", idxPath)
+			}
+			p.offsets[i] = int64(binary.BigEndian.Uint64(idx[at:]))
+			continue
+		}
+		p.offsets[i] = int64(offset)
+	}
+	return p, nil
+}
+
+// find returns the offset of h inside the pack using the fanout table to narrow the binary search
+func (p *gitPack) find(h gitHash) (int64, bool) {
+	lo := 0
+	if h[0] > 0 {
+		lo = int(p.fanout[h[0]-1])
+	}
+	hi := int(p.fanout[h[0]])
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch bytes.Compare(p.names[mid][:], h[:]) {
+		case 0:
+			return p.offsets[mid], true
+		case -1:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, false
+}
+
+// readPackObject returns the type and contents of the object at offset, resolving any chain of deltas
+func (g *gitRepo) readPackObject(p *gitPack, offset int64) (int, []byte, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return g.readPackObjectAt(p, f, offset, 0)
+}
+
+// readPackObjectAt reads the object header at offset of the open pack f and inflates its contents
+func (g *gitRepo) readPackObjectAt(p *gitPack, f *os.File, offset int64, depth int) (int, []byte, error) {
+	if depth > 64 {
+		return 0, nil, errors.New("delta chain is too deep")
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	objType := int(c>>4) & 7
+	for c&0x80 != 0 { // the remaining bits of the inflated size are not needed to inflate the object
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+	var base []byte
+	baseType := 0
+	switch objType {
+	case gitObjOfsDelta:
+		c, err = r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+		baseType, base, err = g.readPackObjectAt(p, f, offset-rel, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+	case gitObjRefDelta:
+		var h gitHash
+		if _, err = io.ReadFull(r, h[:]); err != nil {
+			return 0, nil, err
+		}
+		var kind string
+		kind, base, err = g.readObject(h)
+		if err != nil {
+			return 0, nil, err
+		}
+		for t, k := range gitObjectKinds {
+			if k == kind {
+				baseType = t
+			}
+		}
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+	if base == nil {
+		return objType, data, nil
+	}
+	data, err = applyGitDelta(base, data)
+	return baseType, data, err
+}
+
+// applyGitDelta rebuilds an object from its base and a pack delta of copy and insert instructions
+func applyGitDelta(base, delta []byte) ([]byte, error) {
+	readSize := func() (int, error) {
+		size, shift := 0, 0
+		for {
+			if len(delta) == 0 {
+				return 0, errors.New("delta is truncated")
+			}
+			c := delta[0]
+			delta = delta[1:]
+			size |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return size, nil
+			}
+		}
+	}
+	srcSize, err := readSize()
+	if err != nil {
+		return nil, err
+	}
+	if srcSize != len(base) {
+		return nil, errors.New("delta base size mismatch")
+	}
+	dstSize, err := readSize()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, dstSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			if op == 0 || int(op) > len(delta) {
+				return nil, errors.New("invalid delta insert")
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+		var offset, size int
+		for i := 0; i < 4; i++ {
+			if op&(1<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errors.New("delta is truncated")
+				}
+				offset |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if op&(1<<(4+i)) != 0 {
+				if len(delta) == 0 {
+					return nil, errors.New("delta is truncated")
+				}
+				size |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if offset+size > len(base) {
+			return nil, errors.New("delta copy is out of range")
+		}
+		out = append(out, base[offset:offset+size]...)
+	}
+	if len(out) != dstSize {
+		return nil, errors.New("delta result size mismatch")
+	}
+	return out, nil
+}
@@ -0,0 +1,165 @@
+package summarize
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gitCommand runs git with the args inside dir without any global or system configuration
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=summarize", "GIT_AUTHOR_EMAIL=summarize@example.com",
+		"GIT_COMMITTER_NAME=summarize", "GIT_COMMITTER_EMAIL=summarize@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// gitFixture builds a repository with a main branch that moved on after the feature branch that is checked out was
+// made from it, along with an edit to the worktree, a staged file and an untracked file. The files are long and
+// change a single line at a time, so that git gc stores most of their versions as deltas.
+func gitFixture(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	long := func(name string, changed int) string {
+		var sb strings.Builder
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(&sb, "// line %d of %s\n", i, name)
+			if i == changed {
+				sb.WriteString("// changed\n")
+			}
+		}
+		return sb.String()
+	}
+	commit := func(message string, files map[string]string) {
+		writeTree(t, dir, files)
+		gitCommand(t, dir, "add", "-A")
+		gitCommand(t, dir, "commit", "-q", "-m", message)
+	}
+	gitCommand(t, dir, "init", "-q", "-b", "main")
+	commit("initial", map[string]string{
+		"a.go":          long("a.go", -1),
+		"c.go":          long("c.go", -1),
+		"pkg/b.go":      long("pkg/b.go", -1),
+		"pkg/bar.go":    long("pkg/bar.go", -1),
+		"pkg/sub/z.go":  long("pkg/sub/z.go", -1),
+		"docs/guide.md": "# Guide\n",
+	})
+	gitCommand(t, dir, "branch", "feature")
+	commit("main moves on", map[string]string{"c.go": long("c.go", 10)})
+	gitCommand(t, dir, "tag", "-a", "v1", "-m", "v1")
+	gitCommand(t, dir, "checkout", "-q", "feature")
+	commit("feature", map[string]string{"a.go": long("a.go", 20), "pkg/new.go": long("pkg/new.go", -1)})
+	writeTree(t, dir, map[string]string{
+		"pkg/b.go":     long("pkg/b.go", 30),
+		"staged.go":    "package staged\n",
+		"untracked.go": "package untracked\n",
+	})
+	gitCommand(t, dir, "add", "staged.go")
+	return dir
+}
+
+func TestGitPaths(t *testing.T) {
+	tracked := []string{"a.go", "c.go", "docs/guide.md", "pkg/b.go", "pkg/bar.go", "pkg/new.go", "pkg/sub/z.go", "staged.go"}
+	tests := []struct {
+		name      string
+		mode      string
+		rev       string
+		mergeBase bool
+		want      []string
+	}{
+		{"tracked", GitModeTracked, "", false, tracked},
+		{"staged", GitModeStaged, "", false, []string{"staged.go"}},
+		// untracked.go is left out of every mode since it is not in the index
+		{"changed since HEAD", GitModeChanged, "HEAD", false, []string{"pkg/b.go", "staged.go"}},
+		{"changed since the parent", GitModeChanged, "HEAD~1", false, []string{"a.go", "pkg/b.go", "pkg/new.go", "staged.go"}},
+		{"changed since main", GitModeChanged, "main", false, []string{"a.go", "c.go", "pkg/b.go", "pkg/new.go", "staged.go"}},
+		{"changed since a tag", GitModeChanged, "v1", false, []string{"a.go", "c.go", "pkg/b.go", "pkg/new.go", "staged.go"}},
+		{"changed since the merge-base of main", GitModeChanged, "main", true, []string{"a.go", "pkg/b.go", "pkg/new.go", "staged.go"}},
+	}
+	layouts := []struct {
+		name    string
+		prepare func(t *testing.T, dir string)
+	}{
+		{"loose objects", func(t *testing.T, dir string) {}},
+		{"packed with offset deltas", func(t *testing.T, dir string) {
+			gitCommand(t, dir, "gc", "-q")
+		}},
+		{"packed with ref deltas", func(t *testing.T, dir string) {
+			gitCommand(t, dir, "config", "pack.useDeltaBaseOffset", "false")
+			gitCommand(t, dir, "gc", "-q")
+		}},
+		{"index v4", func(t *testing.T, dir string) {
+			gitCommand(t, dir, "update-index", "--index-version", "4")
+		}},
+	}
+	for _, layout := range layouts {
+		t.Run(layout.name, func(t *testing.T) {
+			dir := gitFixture(t)
+			layout.prepare(t, dir)
+			if strings.HasPrefix(layout.name, "packed") {
+				packs, _ := filepath.Glob(filepath.Join(dir, ".git", "objects", "pack", "*.idx"))
+				if len(packs) == 0 || !strings.Contains(gitCommand(t, dir, "verify-pack", "-v", packs[0]), "chain length") {
+					t.Fatal("git gc did not write a pack with deltas")
+				}
+				if _, err := os.Stat(filepath.Join(dir, ".git", "packed-refs")); err != nil {
+					t.Fatal("git gc did not pack the refs")
+				}
+			}
+			g, err := openGitRepo(filepath.Join(dir, "pkg"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, err := g.gitPaths(tt.mode, tt.rev, tt.mergeBase)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !slices.Equal(got, tt.want) {
+						t.Errorf("gitPaths(%q, %q, %v) = %v, want %v", tt.mode, tt.rev, tt.mergeBase, got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestGitResolve(t *testing.T) {
+	dir := gitFixture(t)
+	g, err := openGitRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rev := range []string{"HEAD", "feature", "main", "v1", "HEAD~1", "HEAD^", "main~1", "refs/heads/main"} {
+		want := strings.TrimSpace(gitCommand(t, dir, "rev-parse", rev+"^{commit}"))
+		for _, name := range []string{rev, want, want[:10]} {
+			h, err := g.resolve(name)
+			if err != nil {
+				t.Errorf("resolve(%q) returned %v", name, err)
+				continue
+			}
+			if h.String() != want {
+				t.Errorf("resolve(%q) = %s, want %s", name, h, want)
+			}
+		}
+	}
+	for _, rev := range []string{"missing", "HEAD~5", "HEAD^2"} {
+		if _, err := g.resolve(rev); err == nil {
+			t.Errorf("resolve(%q) returned no error", rev)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package summarize
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// newIgnorer returns an ignorer for the root that has not loaded any ignore files yet
+func newIgnorer(root string) *ignorer {
+	return &ignorer{
+		root:  root,
+		files: make(map[string][]*ignoreFile),
+	}
+}
+
+// load parses each of the names inside the dir (relative to the root) and stores the ignoreFile for the dir in order
+func (g *ignorer) load(dir string, names ...string) error {
+	for _, name := range names {
+		ignorePath := filepath.Join(g.root, filepath.FromSlash(dir), name)
+		contents, err := os.ReadFile(ignorePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading ignore file %s: %w", ignorePath, err)
+		}
+		f, err := parseIgnore(path.Join(dir, name), dir, contents)
+		if err != nil {
+			return err
+		}
+		g.files[dir] = append(g.files[dir], f)
+	}
+	return nil
+}
+
+// ignored walks the ignore files from the root down to the parent of rel (a slash separated path relative to the
+// root) and returns whether the last matching rule ignores rel, along with a description of that rule
+func (g *ignorer) ignored(rel string, isDir bool) (bool, string) {
+	if len(g.files) == 0 || rel == "." || len(rel) == 0 {
+		return false, ""
+	}
+	ignored, reason := false, ""
+	dirs := []string{""}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		dirs = append(dirs, strings.Join(parts[:i], "/"))
+	}
+	for _, dir := range dirs {
+		sub := rel
+		if len(dir) > 0 {
+			sub = strings.TrimPrefix(rel, dir+"/")
+		}
+		for _, f := range g.files[dir] {
+			if rule := f.match(sub, isDir); rule != nil {
+				ignored = !rule.negate
+				reason = fmt.Sprintf("%s:%d %s", f.path, rule.line, rule.pattern)
+			}
+		}
+	}
+	return ignored, reason
+}
+
+// match returns the last rule of f that matches the sub path relative to f.dir, which is a directory when isDir is
+// set, or nil when no rule matches it
+func (f *ignoreFile) match(sub string, isDir bool) *ignoreRule {
+	var last *ignoreRule
+	for i := range f.rules {
+		rule := &f.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(sub) {
+			last = rule
+		}
+	}
+	return last
+}
+
+// parseIgnore reads the gitignore syntax contents of the ignore file at name whose patterns are relative to dir
+func parseIgnore(name, dir string, contents []byte) (*ignoreFile, error) {
+	f := &ignoreFile{path: name, dir: dir}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	line := 0
+	for scanner.Scan() {
+		line++
+		pattern := trimIgnoreLine(scanner.Text())
+		if len(pattern) == 0 || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		rule := ignoreRule{pattern: pattern, line: line}
+		if strings.HasPrefix(pattern, "!") {
+			rule.negate = true
+			pattern = pattern[1:]
+		} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rule.dirOnly = true
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		if len(pattern) == 0 {
+			continue
+		}
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s:%d %q: %w", name, line, rule.pattern, err)
+		}
+		rule.re = re
+		f.rules = append(f.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// compileGlob compiles a gitignore style glob into a regular expression that matches slash separated relative paths.
+// A glob with a separator at the beginning or in the middle is anchored to the start of the path, otherwise it
+// matches at any depth.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(glob, "/")
+	expr := globToRegexp(strings.TrimPrefix(glob, "/"))
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+	return regexp.Compile(expr)
+}
+
+// trimIgnoreLine removes the trailing whitespace of a gitignore line unless it is escaped with a backslash
+func trimIgnoreLine(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t") {
+		if strings.HasSuffix(line, `\ `) {
+			return line[:len(line)-2] + " "
+		}
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// globToRegexp converts a gitignore glob into a regular expression where "*", "?" and "[...]" never match a "/" and
+// "**" matches across directories
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch c {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				leading := i == 0 || runes[i-1] == '/'
+				switch {
+				case leading && i+2 < len(runes) && runes[i+2] == '/':
+					sb.WriteString("(?:.*/)?") // "**/" matches zero or more directories
+					i += 2
+				case leading && i+2 == len(runes):
+					sb.WriteString(".*") // "/**" matches everything inside
+					i++
+				default:
+					sb.WriteString("[^/]*")
+					i++
+				}
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := string(runes[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = end
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
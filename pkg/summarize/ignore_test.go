@@ -0,0 +1,121 @@
+package summarize
+
+import "testing"
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.log", "debug.log", true},
+		{"*.log", "logs/debug.log", true},
+		{"*.log", "debug.log.bak", false},
+		{"/build", "build", true},
+		{"/build", "src/build", false},
+		{"doc/*.txt", "doc/notes.txt", true},
+		{"doc/*.txt", "doc/sub/notes.txt", false},
+		{"doc/*.txt", "src/doc/notes.txt", false},
+		{"**/foo", "foo", true},
+		{"**/foo", "a/b/foo", true},
+		{"**/foo", "a/foobar", false},
+		{"abc/**", "abc/x", true},
+		{"abc/**", "abc/x/y", true},
+		{"abc/**", "abc", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/bb", false},
+		{"src/*.go", "src/pkg/main.go", false},
+		{"?.go", "a.go", true},
+		{"?.go", "ab.go", false},
+		{"[abc].go", "b.go", true},
+		{"[abc].go", "d.go", false},
+		{"[!abc].go", "d.go", true},
+		{"[!abc].go", "a.go", false},
+		{"[a-c]x", "bx", true},
+		{`foo\*`, "foo*", true},
+		{`foo\*`, "foobar", false},
+		{`\[x]`, "[x]", true},
+		{"a+b(c).go", "a+b(c).go", true},
+		{"a+b(c).go", "aab(c).go", false},
+		{"[unclosed", "[unclosed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.path, func(t *testing.T) {
+			re, err := compileGlob(tt.glob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := re.MatchString(tt.path); got != tt.match {
+				t.Errorf("compileGlob(%q) matches %q = %v, want %v (%s)", tt.glob, tt.path, got, tt.match, re)
+			}
+		})
+	}
+}
+
+func TestIgnorer(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		".gitignore": "# comment\n" +
+			"*.log\n" +
+			"!keep.log\n" +
+			"/build\n" +
+			"tmp/\n" +
+			"\\#notes\n" +
+			"\\!bang\n" +
+			"space\\ \n" +
+			"trailing.txt   \n" +
+			"generated/**\n" +
+			"!generated/keep.go\n",
+		"sub/.gitignore": "!debug.log\nlocal.go\n",
+	})
+	g := newIgnorer(dir)
+	if err := g.load("", GitIgnoreFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.load("sub", GitIgnoreFile); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		rel     string
+		isDir   bool
+		ignored bool
+	}{
+		{"debug.log", false, true},
+		{"logs/debug.log", false, true},
+		{"keep.log", false, false},
+		{"logs/keep.log", false, false},
+		{"build", true, true},
+		{"build", false, true},
+		{"src/build", true, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"src/tmp", true, true},
+		{"#notes", false, true},
+		{"comment", false, false},
+		{"!bang", false, true},
+		{"space ", false, true},
+		{"space", false, false},
+		{"trailing.txt", false, true},
+		{"generated/a.go", false, true},
+		{"generated/keep.go", false, false},
+		{"sub/debug.log", false, false},
+		{"sub/deeper/debug.log", false, false},
+		{"sub/local.go", false, true},
+		{"sub/deeper/local.go", false, true},
+		{"local.go", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			ignored, reason := g.ignored(tt.rel, tt.isDir)
+			if ignored != tt.ignored {
+				t.Errorf("ignored(%q, %v) = %v by %q, want %v", tt.rel, tt.isDir, ignored, reason, tt.ignored)
+			}
+		})
+	}
+	if _, reason := g.ignored("sub/local.go", false); reason != "sub/.gitignore:2 local.go" {
+		t.Errorf("sub/local.go is ignored by %q, want the rule on line 2 of sub/.gitignore", reason)
+	}
+}
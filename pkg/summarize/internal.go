@@ -0,0 +1,502 @@
+package summarize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// debugf writes to the Options.DebugWriter when Options.Debug is enabled
+func (r *run) debugf(format string, a ...any) {
+	if !r.opts.Debug {
+		return
+	}
+	_, _ = fmt.Fprintf(r.opts.DebugWriter, format, a...)
+}
+
+// debugData prints each extension in data with the paths that were received
+func (r *run) debugData() {
+	r.debugf("data received: \n")
+	r.data.Range(func(e any, p any) bool {
+		ext, ok := e.(string)
+		if !ok {
+			return true // continue
+		}
+		thisData, ok := p.(mapData)
+		if !ok {
+			return true // continue
+		}
+		r.debugf("%s: %s\n", ext, strings.Join(thisData.Paths, ", "))
+		return true // continue
+	})
+}
+
+// analyze is called from investigate where the path is inspected and the results are written to
+func (r *run) analyze(ext, filePath string) {
+	defer r.maxFileSemaphore.Release() // maxFileSemaphore prevents excessive files from being opened
+	defer r.wg.Done()                  // keep the Run waiting while this file is being processed
+	name := r.locate(filePath)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		r.omit(name, 0, OmitReadError)
+		r.errs.Add(&Error{Kind: KindRead, Path: name, Err: err})
+		return
+	}
+	if excludedName(filePath) {
+		r.omit(name, info.Size(), OmitExcluded)
+		return
+	}
+	entry, ok := r.cached(filePath, info)
+	if !ok {
+		content, err := os.ReadFile(filePath) // open the file and get its contents
+		if err != nil {
+			r.omit(name, info.Size(), OmitReadError)
+			r.errs.Add(&Error{Kind: KindRead, Path: name, Err: err})
+			return
+		}
+		entry = r.prepare(filePath, name, info, content)
+	}
+	if len(entry.Omit) > 0 {
+		r.debugf("skipping %s: %s\n", filePath, entry.Omit)
+		r.omit(name, info.Size(), entry.Omit)
+		return
+	}
+	if len(entry.Redactions) > 0 && r.opts.FailOnSecret {
+		found := entry.Redactions[0]
+		r.cancel(fmt.Errorf("%w: %s in %s:%d", ErrSecretFound, found.Kind, found.Path, found.Line))
+		return
+	}
+	r.redacted(entry.Redactions)
+	r.seen.Add(filePath)
+	result := Result{
+		File: File{
+			Path:       name,
+			Language:   ext,
+			Size:       info.Size(),
+			Mode:       info.Mode(),
+			ModTime:    info.ModTime(),
+			Hash:       entry.Hash,
+			Tokens:     entry.Tokens,
+			Truncation: entry.Truncation,
+		},
+		Contents: entry.Contents,
+	}
+	if result.rendered, err = r.renderer.file(result); err != nil {
+		r.errs.Add(&Error{Kind: KindRender, Path: name, Err: err})
+		return
+	}
+	if entry.Cost == 0 || entry.Language != ext {
+		// the rendered tokens depend on the type the file was classified as as well as its contents
+		entry.Cost, entry.Language = r.opts.Tokenizer.Count(result.rendered), ext
+		r.store(filePath, entry)
+	}
+	result.cost = entry.Cost
+	r.results <- result
+}
+
+// excludedName reports whether the path is a .DS_Store, an .exe or a binary named after an architecture, which are
+// never summarized
+func excludedName(path string) bool {
+	return strings.HasSuffix(path, ".DS_Store") ||
+		strings.HasSuffix(path, ".exe") ||
+		strings.HasSuffix(path, "-amd64") ||
+		strings.HasSuffix(path, "-arm64") ||
+		strings.HasSuffix(path, "aarch64")
+}
+
+// prepare sniffs, redacts, truncates and tokenizes the content of the file at path, which is rendered as name, into a
+// cacheEntry, unless the content was already cached under the same hash
+func (r *run) prepare(path, name string, info fs.FileInfo, content []byte) cacheEntry {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	if entry, ok := r.cachedContent(path, info, hash); ok {
+		return entry
+	}
+	entry := cacheEntry{Size: info.Size(), ModTime: info.ModTime(), Mode: info.Mode(), Hash: hash}
+	if entry.Omit = r.sniff(content); len(entry.Omit) > 0 {
+		r.store(path, entry)
+		return entry
+	}
+	if r.opts.Redact || r.opts.FailOnSecret {
+		content, entry.Redactions = r.redact(name, content)
+	}
+	content, entry.Truncation = r.truncate(name, content)
+	entry.Contents = string(content)
+	entry.Tokens = r.opts.Tokenizer.Count(content)
+	return entry
+}
+
+// iterate is a func that gets passed directly into the data.Range(iterate) that runs investigate concurrently with the
+// wg and throttler enabled
+func (r *run) iterate(e, p any) bool {
+	ext, ok := e.(string)
+	if !ok {
+		return true // continue
+	}
+	thisData, ok := p.(mapData)
+	if !ok {
+		return true // continue
+	}
+	paths := slices.Clone(thisData.Paths)
+
+	r.throttler.Acquire() // throttler is used to protect the runtime from excessive use
+	r.wg.Add(1)           // wg is used to prevent the Run from returning early
+	go r.investigate(&thisData, ext, paths)
+	return true
+}
+
+// investigate is called from iterate where it takes an extension and a slice of paths to analyze each path
+func (r *run) investigate(innerData *mapData, ext string, paths []string) {
+	defer r.throttler.Release() // when we're done, release the throttler
+	defer r.wg.Done()           // then tell the sync.WaitGroup that we are done
+
+	paths = simplify(paths)
+
+	innerData.Paths = paths
+	r.toUpdateMu.Lock()
+	r.toUpdate = append(r.toUpdate, *innerData)
+	r.toUpdateMu.Unlock()
+
+	// process each file in the ext list (one ext per throttle slot in the semaphore)
+	for _, filePath := range paths {
+		if r.seen.Exists(filePath) || r.ctx.Err() != nil {
+			continue
+		}
+		r.maxFileSemaphore.Acquire()
+		r.wg.Add(1)
+		go r.analyze(ext, filePath)
+	}
+}
+
+// populate stores the path in the *sync.Map called data under the first included extension that its Classify kind
+// matches, so that every file is analyzed once with the fence of its own type, and leaves out the kinds that are not
+// included
+func (r *run) populate(kind, path string) {
+	for _, ext := range r.opts.IncludeExt {
+		if !strings.EqualFold(ext, kind) {
+			continue
+		}
+		p, _ := r.data.Load(ext)
+		value, _ := p.(mapData)
+		value.Ext = ext
+		value.Paths = append(value.Paths, path)
+		r.data.Store(ext, value)
+		return
+	}
+	r.debugf("not including %s of type %q\n", path, kind)
+}
+
+// loadIgnores reads the ignore files of the dir (relative to the SourceDir) that are enabled in the Options
+func (r *run) loadIgnores(dir string) error {
+	var names []string
+	if r.opts.GitIgnore {
+		names = append(names, GitIgnoreFile)
+	}
+	if len(dir) == 0 {
+		if r.opts.DockerIgnore {
+			names = append(names, DockerIgnoreFile)
+		}
+		if r.opts.SummarizeIgnore {
+			names = append(names, SummarizeIgnoreFile)
+		}
+	}
+	return r.ignore.load(dir, names...)
+}
+
+// summarizeGit sends each file selected from the git repository of the Root being walked through summarize as if it
+// was walked
+func (r *run) summarizeGit() error {
+	repo, err := openGitRepo(r.root.Dir)
+	if err != nil {
+		return err
+	}
+	paths, err := repo.gitPaths(r.opts.Git, r.opts.GitSince, r.opts.GitMergeBase)
+	if err != nil {
+		return err
+	}
+	prefix, err := filepath.Rel(repo.worktree, workspace(r.root.Dir))
+	if err != nil {
+		return err
+	}
+	prefix = filepath.ToSlash(prefix)
+	r.debugf("git %s selected %d files in %s\n", r.opts.Git, len(paths), repo.worktree)
+	loaded := make(map[string]bool)
+	for _, repoPath := range paths {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		rel := repoPath
+		if prefix != "." {
+			var ok bool
+			if rel, ok = strings.CutPrefix(repoPath, prefix+"/"); !ok {
+				continue // outside of the Root
+			}
+		}
+		ignored, err := r.ignoredDir(rel, loaded)
+		if err != nil {
+			return err
+		}
+		if ignored {
+			continue
+		}
+		path := filepath.Join(r.root.Dir, filepath.FromSlash(rel))
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			r.debugf("skipping deleted %s\n", path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.summarize(path, fs.FileInfoToDirEntry(info), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ignoredDir loads the ignore files of every directory above rel that has not been loaded yet and reports whether any
+// of those directories is ignored, which is what the walk would have found before reaching rel
+func (r *run) ignoredDir(rel string, loaded map[string]bool) (bool, error) {
+	dir := ""
+	parts := strings.Split(rel, "/")
+	for i := 0; i < len(parts); i++ {
+		if i > 0 {
+			dir = strings.Join(parts[:i], "/")
+			if ignored, reason := r.ignore.ignored(dir, true); ignored {
+				r.debugf("ignoring %s in directory %s (%s)\n", rel, dir, reason)
+				return true, nil
+			}
+		}
+		if loaded[dir] {
+			continue
+		}
+		loaded[dir] = true
+		if err := r.loadIgnores(dir); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// receive accepts each Result from results and, once all of them are sorted by the Options.Order, writes them to the
+// summary buffer until the Options.MaxOutputSize or the Options.MaxTokens is reached.
+func (r *run) receive() {
+	defer r.writerWG.Done()
+
+	// every result is received before any is written so that the order does not depend on goroutine scheduling
+	var received []Result
+	renderedPaths := make(map[string]bool)
+	for in := range r.results {
+		if renderedPaths[in.Path] {
+			continue
+		}
+		renderedPaths[in.Path] = true
+		received = append(received, in)
+	}
+	r.order(received)
+
+	// the header lists every received file while the budgets are filled, and only the rendered files once they are
+	h := header{
+		Project:      ProjectName,
+		Version:      r.opts.Version,
+		Filename:     r.opts.Filename,
+		Instructions: Instructions,
+		Files:        files(received),
+	}
+	h.Workspace, h.Roots = workspaces(r.opts)
+	if r.opts.Chunk {
+		r.chunk(received, h)
+		return
+	}
+	head, err := r.renderer.header(h)
+	if err != nil {
+		r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering header: %w", err)})
+	}
+	separator := r.renderer.separator()
+	separatorTokens := r.opts.Tokenizer.Count(separator)
+	totalSize := int64(len(head))
+	r.tokens = r.opts.Tokenizer.Count(head)
+	if r.opts.Pack {
+		received = r.pack(received, totalSize, r.tokens)
+	}
+	for _, in := range received {
+		size, tokens := int64(len(in.rendered)), in.cost
+		if len(r.rendered) > 0 {
+			size, tokens = size+int64(len(separator)), tokens+separatorTokens
+		}
+		if totalSize+size >= r.opts.MaxOutputSize {
+			r.debugf("size: %d would exceed %d, skipping %s\n", totalSize+size, r.opts.MaxOutputSize, in.Path)
+			r.omit(in.Path, in.Size, OmitSizeBudget)
+			continue
+		}
+		if r.opts.MaxTokens > 0 && r.tokens+tokens > r.opts.MaxTokens {
+			r.debugf("tokens: %d would exceed %d, skipping %s\n", r.tokens+tokens, r.opts.MaxTokens, in.Path)
+			r.omit(in.Path, in.Size, OmitTokenBudget)
+			continue
+		}
+		totalSize += size // only the files that were written count towards the MaxOutputSize
+		r.tokens += tokens
+		r.rendered = append(r.rendered, in)
+		if in.Truncation != nil {
+			r.truncated = append(r.truncated, *in.Truncation)
+		}
+		r.debugf("tokens: %d %s\n", tokens, in.Path)
+	}
+	if len(r.rendered) < len(received) {
+		r.tokens -= r.opts.Tokenizer.Count(head)
+		h.Files = files(r.rendered)
+		if head, err = r.renderer.header(h); err != nil {
+			r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering header: %w", err)})
+		}
+		r.tokens += r.opts.Tokenizer.Count(head)
+	}
+	r.buf.Write(head)
+	for i, in := range r.rendered {
+		if i > 0 {
+			r.buf.Write(separator)
+		}
+		r.buf.Write(in.rendered)
+	}
+	foot, err := r.renderer.footer(r.report())
+	if err != nil {
+		r.errs.Add(&Error{Kind: KindRender, Err: fmt.Errorf("rendering footer: %w", err)})
+	}
+	r.buf.Write(foot)
+	r.tokens += r.opts.Tokenizer.Count(foot)
+	r.debugf("tokens: %d total (%s)\n", r.tokens, r.opts.Tokenizer.Name())
+}
+
+// files returns the File of each of the results
+func files(results []Result) []File {
+	out := make([]File, 0, len(results))
+	for _, in := range results {
+		out = append(out, in.File)
+	}
+	return out
+}
+
+// report sorts the truncated, redacted, omitted and failed files by path into the footer of the summary
+func (r *run) report() footer {
+	r.omitMu.Lock()
+	defer r.omitMu.Unlock()
+	r.redactMu.Lock()
+	defer r.redactMu.Unlock()
+	slices.SortFunc(r.truncated, func(a, b Truncation) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	slices.SortFunc(r.redactions, func(a, b Redaction) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return a.Line - b.Line
+	})
+	slices.SortFunc(r.omitted, func(a, b Omission) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return footer{
+		Truncated:  append([]Truncation{}, r.truncated...),
+		Redactions: append([]Redaction{}, r.redactions...),
+		Omitted:    append([]Omission{}, r.omitted...),
+		Errors:     r.errs.FileErrors(),
+	}
+}
+
+// omit records that the path of size was left out of the summary for the reason
+func (r *run) omit(path string, size int64, reason string) {
+	r.omitMu.Lock()
+	defer r.omitMu.Unlock()
+	r.omitted = append(r.omitted, Omission{Path: path, Size: size, Reason: reason})
+}
+
+// walk sends every path of each of the Options.Roots through summarize, or the files selected from their git
+// repository when Options.Git is set, with the ignore files of the Root being walked
+func (r *run) walk() error {
+	for _, root := range r.opts.Roots {
+		r.root, r.ignore = root, newIgnorer(root.Dir)
+		if len(r.opts.Git) > 0 {
+			if err := r.summarizeGit(); err != nil {
+				return &Error{Kind: KindWalk, Path: root.Dir, Err: fmt.Errorf("selecting git files: %w", err)}
+			}
+			continue
+		}
+		err := filepath.WalkDir(root.Dir, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := r.ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return r.summarize(path, d, err)
+		})
+		if err != nil {
+			return &Error{Kind: KindWalk, Path: root.Dir, Err: err}
+		}
+	}
+	return nil
+}
+
+// summarize walks through a filepath recursively and matches paths that get stored inside the
+// data *sync.Map for the extension. Directories that decide does not include are pruned with filepath.SkipDir so that
+// nothing inside of them is ever listed or classified.
+func (r *run) summarize(path string, d fs.DirEntry, err error) error {
+	if err != nil {
+		return err // return the error received
+	}
+	rel, err := filepath.Rel(r.root.Dir, path)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	e, err := r.decide(path, rel, d)
+	if err != nil {
+		var size int64
+		if info, infoErr := d.Info(); infoErr == nil {
+			size = info.Size()
+		}
+		r.omit(r.aliased(rel), size, OmitReadError)
+		r.errs.Add(&Error{Kind: KindRead, Path: r.aliased(rel), Err: err})
+		if r.list {
+			r.listed = append(r.listed, Listing{Explanation: e.excluded(RuleContent, OmitReadError), Size: size})
+		}
+		return nil // skip with the error recorded
+	}
+	if r.list && (!e.Included || !d.IsDir()) {
+		r.listed = append(r.listed, r.listing(path, d, e))
+	}
+	if !e.Included {
+		r.debugf("skipping %s (%s %s)\n", path, e.Rule, e.Match)
+		if d.IsDir() {
+			return filepath.SkipDir // prune the directory without walking it
+		}
+		return nil // skip without error
+	}
+	if d.IsDir() {
+		return r.loadIgnores(rel)
+	}
+	if r.list {
+		return nil // listed without being analyzed
+	}
+
+	r.debugf("type: %s\n", e.Type)
+	r.names[path] = r.aliased(rel)
+	r.populate(e.Type, path)
+
+	// continue to the next file
+	return nil
+}
+
+// skipped returns the first of the SkipContains that is a substring of the path and reports whether there was one
+func (r *run) skipped(path string) (string, bool) {
+	for _, avoidThis := range r.opts.SkipContains {
+		if strings.Contains(path, avoidThis) {
+			return avoidThis, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,72 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunReportsOmitted(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":      "package a\n",
+		"b.go":      "package b\n",
+		"big.go":    "package big\n\n" + strings.Repeat("// filler line of a file over the size budget\n", 200),
+		"bin.go":    "package\x00bin\n",
+		"gen.go":    "// Code generated by stringer; DO NOT EDIT.\n\npackage gen\n",
+		"long.go":   "package long\n\nvar s = \"" + strings.Repeat("x", 300) + "\"\n",
+		"app.exe":   "MZ",
+		"notes.txt": "not an included type\n",
+	}
+	writeTree(t, dir, files)
+	opts := treeOptions(dir)
+	opts.IncludeExt, opts.Format, opts.MaxOutputSize = []string{"go", "exe"}, FormatJSON, 4096
+	opts.SkipBinary, opts.SkipGenerated, opts.MaxLineLength = true, true, 200
+	opts.ExcludeExt = []string{} // the DefaultExcludeExt would leave app.exe out before it is classified
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Omission{
+		{Path: "app.exe", Reason: OmitExcluded},
+		{Path: "big.go", Reason: OmitSizeBudget},
+		{Path: "bin.go", Reason: OmitBinary},
+		{Path: "gen.go", Reason: OmitGenerated},
+		{Path: "long.go", Reason: OmitLongLines},
+	}
+	for i := range want {
+		want[i].Size = int64(len(files[want[i].Path]))
+	}
+	if !reflect.DeepEqual(summary.Omitted, want) {
+		t.Errorf("Summary.Omitted = %+v, want %+v", summary.Omitted, want)
+	}
+	var included []string
+	for _, result := range summary.Results {
+		included = append(included, result.Path)
+	}
+	slices.Sort(included)
+	if !slices.Equal(included, []string{"a.go", "b.go"}) {
+		t.Errorf("Run summarized %v, want a.go and b.go", included)
+	}
+	// every file of an included type is either summarized or omitted, while notes.txt is neither
+	if len(summary.Results)+len(summary.Omitted) != len(files)-1 {
+		t.Errorf("Run summarized %d and omitted %d of the %d files of an included type", len(summary.Results), len(summary.Omitted), len(files)-1)
+	}
+
+	var doc struct {
+		Omitted []Omission `json:"omitted"`
+	}
+	if err := json.Unmarshal(summary.Contents, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doc.Omitted, want) {
+		t.Errorf("the footer reports %+v as omitted, want %+v", doc.Omitted, want)
+	}
+}
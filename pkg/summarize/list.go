@@ -0,0 +1,65 @@
+package summarize
+
+import (
+	"context"
+	"io/fs"
+	"os"
+)
+
+// List walks the SourceDir, or selects the files of its git repository with Options.Git, through the same rules as
+// Run and returns a Listing of every file it reached and of every directory it pruned, in the order of the walk,
+// without rendering a summary. The included files are read to apply the content checks of the Options and to
+// estimate their tokens, and the files that cannot be read are listed as excluded by RuleContent with their errors
+// joined like the errors of Run.
+func (s *Summarizer) List(ctx context.Context) ([]Listing, error) {
+	r := &run{
+		ctx:     ctx,
+		opts:    s.opts,
+		filters: s.filters,
+		errs:    &collector{},
+		list:    true,
+	}
+	if err := r.walk(); err != nil {
+		return nil, err
+	}
+	return r.listed, r.errs.Err()
+}
+
+// listing returns the Listing of the path that decide explained with e, reading an included file to check its
+// contents and estimate its tokens once it is redacted and truncated the way that prepare would
+func (r *run) listing(path string, d fs.DirEntry, e Explanation) Listing {
+	l := Listing{Explanation: e, IsDir: d.IsDir()}
+	if l.IsDir {
+		return l
+	}
+	info, err := os.Stat(path)
+	if err == nil {
+		l.Size = info.Size()
+	}
+	if !e.Included {
+		return l
+	}
+	if err == nil && excludedName(path) {
+		l.Explanation = e.excluded(RuleContent, OmitExcluded)
+		return l
+	}
+	var content []byte
+	if err == nil {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		r.errs.Add(&Error{Kind: KindRead, Path: e.Path, Err: err})
+		l.Explanation = e.excluded(RuleContent, OmitReadError)
+		return l
+	}
+	if reason := r.sniff(content); len(reason) > 0 {
+		l.Explanation = e.excluded(RuleContent, reason)
+		return l
+	}
+	if r.opts.Redact {
+		content, _ = r.redact(e.Path, content)
+	}
+	content, _ = r.truncate(e.Path, content)
+	l.Tokens = r.opts.Tokenizer.Count(content)
+	return l
+}
@@ -0,0 +1,69 @@
+package summarize
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// order sorts the results in place by the Options.Order, always falling back to the path so that every run over an
+// unchanged tree renders the same summary
+func (r *run) order(results []Result) {
+	compare := func(a, b Result) int {
+		return 0
+	}
+	switch r.opts.Order {
+	case OrderTree:
+		compare = func(a, b Result) int {
+			return compareTree(a.Path, b.Path)
+		}
+	case OrderSize:
+		compare = func(a, b Result) int {
+			return cmp.Compare(a.Size, b.Size)
+		}
+	case OrderModified:
+		compare = func(a, b Result) int {
+			return b.ModTime.Compare(a.ModTime)
+		}
+	case OrderPriority:
+		compare = func(a, b Result) int {
+			return r.priorityOf(a.Path) - r.priorityOf(b.Path)
+		}
+	}
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if c := compare(a, b); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+}
+
+// priorityOf returns the index of the first Options.Priority glob that matches rel, or the number of globs when none
+// of them match so that the remaining files come last
+func (r *run) priorityOf(rel string) int {
+	for i, re := range r.priority {
+		if re.MatchString(rel) {
+			return i
+		}
+	}
+	return len(r.priority)
+}
+
+// compareTree orders slash separated paths depth first, listing the files of a directory before its subdirectories
+func compareTree(a, b string) int {
+	ap, bp := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		if ap[i] == bp[i] {
+			continue
+		}
+		aDir, bDir := i < len(ap)-1, i < len(bp)-1
+		if aDir != bDir {
+			if aDir {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(ap[i], bp[i])
+	}
+	return len(ap) - len(bp)
+}
@@ -0,0 +1,63 @@
+package summarize
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOrder(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	results := []Result{
+		{File: File{Path: "main.go", Size: 300, ModTime: now}},
+		{File: File{Path: "a/z.go", Size: 100, ModTime: now.Add(-time.Hour)}},
+		{File: File{Path: "a/b/c.go", Size: 100, ModTime: now}},
+		{File: File{Path: "a/b.go", Size: 200, ModTime: now.Add(time.Hour)}},
+		{File: File{Path: "README.md", Size: 100, ModTime: now.Add(-time.Hour)}},
+		{File: File{Path: "b/x_test.go", Size: 50, ModTime: now}},
+		{File: File{Path: "z.go", Size: 300, ModTime: now}},
+	}
+	tests := []struct {
+		name     string
+		order    string
+		priority []string
+		want     []string
+	}{
+		{"path", OrderPath, nil, []string{"README.md", "a/b.go", "a/b/c.go", "a/z.go", "b/x_test.go", "main.go", "z.go"}},
+		{"tree", OrderTree, nil, []string{"README.md", "main.go", "z.go", "a/b.go", "a/z.go", "a/b/c.go", "b/x_test.go"}},
+		// the files of the same size are sorted by path
+		{"size", OrderSize, nil, []string{"b/x_test.go", "README.md", "a/b/c.go", "a/z.go", "a/b.go", "main.go", "z.go"}},
+		// the files modified at the same time are sorted by path
+		{"mtime", OrderModified, nil, []string{"a/b.go", "a/b/c.go", "b/x_test.go", "main.go", "z.go", "README.md", "a/z.go"}},
+		{"priority", OrderPriority, []string{"README.md", "*_test.go", "a/**"},
+			[]string{"README.md", "b/x_test.go", "a/b.go", "a/b/c.go", "a/z.go", "main.go", "z.go"}},
+		// a glob that matches nothing leaves the files that it would have ranked in path order
+		{"priority glob that matches nothing", OrderPriority, []string{"docs/**", "main.go"},
+			[]string{"main.go", "README.md", "a/b.go", "a/b/c.go", "a/z.go", "b/x_test.go", "z.go"}},
+		{"no priority globs", OrderPriority, nil, []string{"README.md", "a/b.go", "a/b/c.go", "a/z.go", "b/x_test.go", "main.go", "z.go"}},
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priority, err := compileGlobs("priority", tt.priority)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &run{opts: Options{Order: tt.order}, priority: priority}
+			// every permutation of the same results renders in the same order
+			for range 10 {
+				shuffled := slices.Clone(results)
+				rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+				r.order(shuffled)
+				var got []string
+				for _, in := range shuffled {
+					got = append(got, in.Path)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Fatalf("order %s = %v, want %v", tt.order, got, tt.want)
+				}
+			}
+		})
+	}
+}
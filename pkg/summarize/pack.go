@@ -0,0 +1,88 @@
+package summarize
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// compileGlobs compiles each of the globs in order, describing the kind of glob in the error
+func compileGlobs(kind string, globs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		re, err := compileGlob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s glob %q: %w", kind, glob, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// rank scores rel for packing: the first matching Options.Priority glob is worth the most, entry points get a boost,
+// tests, fixtures and vendored code are demoted, and deeper paths lose a little
+func (r *run) rank(rel string) int {
+	score := 0
+	if i := r.priorityOf(rel); i < len(r.priority) {
+		score += (len(r.priority) - i) * packPriorityWeight
+	}
+	for _, re := range r.entryPoints {
+		if re.MatchString(rel) {
+			score += packEntryPointBoost
+			break
+		}
+	}
+	for _, re := range r.demote {
+		if re.MatchString(rel) {
+			score -= packDemotePenalty
+			break
+		}
+	}
+	return score - strings.Count(rel, "/")
+}
+
+// pack greedily selects the highest ranked results that fit within the Options.MaxOutputSize and Options.MaxTokens
+// after the header of size and tokens. The selected results keep their Options.Order and the rejected results follow
+// them in ranked order, where they fail the same budget checks when the summary is written.
+func (r *run) pack(results []Result, size int64, tokens int) []Result {
+	scores := make(map[string]int, len(results))
+	for _, in := range results {
+		scores[in.Path] = r.rank(in.Path)
+	}
+	ranked := slices.Clone(results)
+	slices.SortStableFunc(ranked, func(a, b Result) int {
+		if c := cmp.Compare(scores[b.Path], scores[a.Path]); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	separator := r.renderer.separator()
+	separatorTokens := r.opts.Tokenizer.Count(separator)
+	selected := make(map[string]bool, len(ranked))
+	var rejected []Result
+	for _, in := range ranked {
+		inSize, inTokens := int64(len(in.rendered)), in.cost
+		if len(selected) > 0 {
+			// every file after the first is written after a separator, which receive counts as well
+			inSize, inTokens = inSize+int64(len(separator)), inTokens+separatorTokens
+		}
+		fits := size+inSize < r.opts.MaxOutputSize && (r.opts.MaxTokens == 0 || tokens+inTokens <= r.opts.MaxTokens)
+		r.debugf("pack: score %d %s (fits: %t)\n", scores[in.Path], in.Path, fits)
+		if !fits {
+			rejected = append(rejected, in)
+			continue
+		}
+		selected[in.Path] = true
+		size += inSize
+		tokens += inTokens
+	}
+	packed := make([]Result, 0, len(results))
+	for _, in := range results {
+		if selected[in.Path] {
+			packed = append(packed, in)
+		}
+	}
+	return append(packed, rejected...)
+}
@@ -0,0 +1,26 @@
+package summarize
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestPackCountsSeparators(t *testing.T) {
+	r := &run{
+		opts:     Options{MaxOutputSize: 32, Tokenizer: CharTokenizer{CharsPerToken: DefaultCharsPerToken}},
+		renderer: jsonRenderer{},
+	}
+	// b.go and c.go rank above a/deep.go, and the ",\n" between them leaves no room for a/deep.go
+	var results []Result
+	for _, path := range []string{"a/deep.go", "b.go", "c.go"} {
+		results = append(results, Result{File: File{Path: path}, rendered: bytes.Repeat([]byte("x"), 10)})
+	}
+	var got []string
+	for _, in := range r.pack(results, 0, 0) {
+		got = append(got, in.Path)
+	}
+	if want := []string{"b.go", "c.go", "a/deep.go"}; !slices.Equal(got, want) {
+		t.Errorf("pack = %v, want %v with a/deep.go rejected", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+package summarize
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// markdownWorkspace finds the workspace in the header of the DefaultTemplate
+var markdownWorkspace = regexp.MustCompile("(?m)^### Workspace\n\n`([^`\n]*)`$")
+
+// Parse reads a summary rendered in any Options.Format back into a Document. FormatMarkdown can only be parsed when it
+// was rendered by the DefaultTemplate. The pieces of a file that Options.Chunk split across parts are joined when the
+// parts are parsed together.
+func Parse(contents []byte) (*Document, error) {
+	trimmed := bytes.TrimSpace(contents)
+	var doc *Document
+	var err error
+	switch {
+	case len(trimmed) == 0:
+		return nil, errors.New("summary is empty")
+	case trimmed[0] == '<':
+		doc, err = parseXML(contents)
+	case trimmed[0] == '{' && json.Valid(trimmed):
+		doc, err = parseJSON(contents)
+	case trimmed[0] == '{':
+		doc, err = parseJSONL(contents)
+	case trimmed[0] == '#':
+		doc, err = parseMarkdown(contents)
+	default:
+		doc, err = parseYAML(contents)
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc.Results = join(doc.Results)
+	return doc, nil
+}
+
+// join concatenates the contents of the results that share a path, in order, into a single Result
+func join(results []Result) []Result {
+	index := make(map[string]int)
+	var out []Result
+	for _, in := range results {
+		if i, ok := index[in.Path]; ok {
+			out[i].Contents += in.Contents
+			out[i].Tokens += in.Tokens
+			continue
+		}
+		index[in.Path] = len(out)
+		in.Piece = nil
+		out = append(out, in)
+	}
+	return out
+}
+
+// parseJSON reads a FormatJSON summary
+func parseJSON(contents []byte) (*Document, error) {
+	var doc struct {
+		Workspace string   `json:"workspace"`
+		Files     []Result `json:"files"`
+	}
+	if err := json.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s summary: %w", FormatJSON, err)
+	}
+	return &Document{Format: FormatJSON, Workspace: doc.Workspace, Results: doc.Files}, nil
+}
+
+// parseYAML reads a FormatYAML summary
+func parseYAML(contents []byte) (*Document, error) {
+	var doc struct {
+		Workspace string   `yaml:"workspace"`
+		Files     []Result `yaml:"files"`
+	}
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s summary: %w", FormatYAML, err)
+	}
+	return &Document{Format: FormatYAML, Workspace: doc.Workspace, Results: doc.Files}, nil
+}
+
+// parseJSONL reads a FormatJSONL summary, taking the workspace from its "summary" lines and a Result from every
+// "file" line
+func parseJSONL(contents []byte) (*Document, error) {
+	doc := &Document{Format: FormatJSONL}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(contents)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record struct {
+			Type      string `json:"type"`
+			Workspace string `json:"workspace"`
+			Result
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("parsing %s summary on line %d: %w", FormatJSONL, line, err)
+		}
+		switch record.Type {
+		case "summary":
+			doc.Workspace = record.Workspace
+		case "file":
+			doc.Results = append(doc.Results, record.Result)
+		}
+	}
+	return doc, scanner.Err()
+}
+
+// parseXML reads a FormatXML summary
+func parseXML(contents []byte) (*Document, error) {
+	var doc struct {
+		Workspace string `xml:"workspace,attr"`
+		Documents []struct {
+			File
+			Content string `xml:"document_content"`
+		} `xml:"document"`
+	}
+	if err := xml.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s summary: %w", FormatXML, err)
+	}
+	out := &Document{Format: FormatXML, Workspace: doc.Workspace}
+	for _, d := range doc.Documents {
+		out.Results = append(out.Results, Result{File: d.File, Contents: d.Content})
+	}
+	return out, nil
+}
+
+// parseMarkdown reads a FormatMarkdown summary rendered by the DefaultTemplate. Every file starts with its heading
+// followed by the line naming its path, and its contents end at the last fence before the heading of the next file,
+// so that fences inside of the contents do not end it early.
+func parseMarkdown(contents []byte) (*Document, error) {
+	doc := &Document{Format: FormatMarkdown}
+	text := string(contents)
+	if m := markdownWorkspace.FindStringSubmatch(text); m != nil {
+		doc.Workspace = m[1]
+	}
+	lines := strings.Split(text, "\n")
+	var starts []int
+	for i, line := range lines {
+		if i >= 2 && strings.HasPrefix(lines[i-2], "## ") && markdownPath(line) != "" {
+			starts = append(starts, i)
+		}
+	}
+	for n, start := range starts {
+		end := len(lines)
+		if n+1 < len(starts) {
+			end = starts[n+1] - 2 // the heading of the next file
+		}
+		in, err := parseMarkdownFile(lines[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s summary on line %d: %w", FormatMarkdown, start+1, err)
+		}
+		doc.Results = append(doc.Results, in)
+	}
+	return doc, nil
+}
+
+// parseMarkdownFile reads the File and Contents of a file of the DefaultTemplate from the line naming its path up to
+// the heading of the next file
+func parseMarkdownFile(lines []string) (Result, error) {
+	in := Result{File: File{Path: markdownPath(lines[0])}}
+	// the path is followed by a blank line, the os.Stat JSON, a blank line, "Source Code:", a blank line and the fence
+	i := 1
+	for i < len(lines) && lines[i] != "```json" {
+		i++
+	}
+	stat := i + 1
+	for i < len(lines) && lines[i] != "```" {
+		i++
+	}
+	if i >= len(lines) {
+		return Result{}, fmt.Errorf("missing the os.Stat of %s", in.Path)
+	}
+	var info struct {
+		Size int64       `json:"size"`
+		Mode os.FileMode `json:"mode"`
+	}
+	if err := json.Unmarshal([]byte(strings.Join(lines[stat:i], "\n")), &info); err != nil {
+		return Result{}, fmt.Errorf("parsing the os.Stat of %s: %w", in.Path, err)
+	}
+	in.Size, in.Mode = info.Size, info.Mode
+	i++
+	for i < len(lines) && !strings.HasPrefix(lines[i], "```") {
+		i++
+	}
+	closing := len(lines) - 1
+	for closing > i && lines[closing] != "```" {
+		closing--
+	}
+	if closing <= i {
+		return Result{}, fmt.Errorf("missing the source code of %s", in.Path)
+	}
+	in.Language = strings.TrimPrefix(lines[i], "```")
+	// the template writes a newline between the contents and the closing fence, which the split leaves out
+	in.Contents = strings.Join(lines[i+1:closing], "\n")
+	return in, nil
+}
+
+// markdownPath returns the path of the line that introduces the os.Stat of a file in the DefaultTemplate, or an
+// empty string when the line is something else
+func markdownPath(line string) string {
+	path, ok := strings.CutPrefix(line, "The `os.Stat` for the ")
+	if !ok {
+		return ""
+	}
+	path, _ = strings.CutSuffix(path, " is: ")
+	return path
+}
@@ -0,0 +1,114 @@
+package summarize
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// redactors find the secrets that are replaced by Options.Redact, in order of precedence when two of them match at
+// the same position
+var redactors = []redactor{
+	{kind: RedactPrivateKey, re: regexp.MustCompile(`(?s)-----BEGIN[ A-Z0-9]*PRIVATE KEY[ A-Z]*-----.*?-----END[ A-Z0-9]*PRIVATE KEY[ A-Z]*-----`)},
+	{kind: RedactAWSAccessKey, re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[0-9A-Z]{16}\b`)},
+	{kind: RedactAWSSecretKey, re: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`), group: 1},
+	{kind: RedactGitHubToken, re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{kind: RedactJWT, re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{kind: RedactPassword, re: regexp.MustCompile(`(?im)^[ \t]*(?:export[ \t]+)?[A-Z0-9_]*(?:PASSWORD|PASSWD|PASS|PWD|SECRET|TOKEN)[A-Z0-9_]*[ \t]*=[ \t]*([^\r\n]*?)[ \t]*\r?$`), group: 1, envOnly: true, check: isAssigned},
+	{kind: RedactHighEntropy, re: regexp.MustCompile("[\"'`]([A-Za-z0-9+/_=-]{20,})[\"'`]"), group: 1, check: isHighEntropy},
+}
+
+// redact replaces every secret that the redactors find in the content of path with a "[REDACTED:kind]" placeholder
+// and returns the redacted content along with a Redaction for each secret on the line it was found
+func (r *run) redact(path string, content []byte) ([]byte, []Redaction) {
+	type span struct {
+		start, end int
+		kind       string
+	}
+	var spans []span
+	env := isEnvFile(filepath.Base(path))
+	for _, rd := range redactors {
+		if rd.envOnly && !env {
+			continue
+		}
+		for _, m := range rd.re.FindAllSubmatchIndex(content, -1) {
+			start, end := m[2*rd.group], m[2*rd.group+1]
+			if start < 0 || start == end || (rd.check != nil && !rd.check(content[start:end])) {
+				continue
+			}
+			spans = append(spans, span{start: start, end: end, kind: rd.kind})
+		}
+	}
+	if len(spans) == 0 {
+		return content, nil
+	}
+	slices.SortStableFunc(spans, func(a, b span) int {
+		return a.start - b.start
+	})
+	var out bytes.Buffer
+	var found []Redaction
+	last, counted, line := 0, 0, 1
+	for _, s := range spans {
+		if s.start < last {
+			continue // overlaps a secret that was already redacted
+		}
+		line += bytes.Count(content[counted:s.start], []byte("\n"))
+		counted = s.start
+		out.Write(content[last:s.start])
+		out.WriteString("[REDACTED:" + s.kind + "]")
+		last = s.end
+		found = append(found, Redaction{Path: path, Line: line, Kind: s.kind})
+	}
+	out.Write(content[last:])
+	return out.Bytes(), found
+}
+
+// redacted records the secrets found in a file for the redaction report
+func (r *run) redacted(found []Redaction) {
+	r.redactMu.Lock()
+	defer r.redactMu.Unlock()
+	for _, f := range found {
+		r.debugf("redacted %s in %s:%d\n", f.Kind, f.Path, f.Line)
+	}
+	r.redactions = append(r.redactions, found...)
+}
+
+// isEnvFile reports whether name is a .env style file such as .env, .env.local or production.env
+func isEnvFile(name string) bool {
+	return name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env")
+}
+
+// isAssigned reports whether the value of an assignment is set to something other than an empty string
+func isAssigned(value []byte) bool {
+	return !bytes.Equal(value, []byte(`""`)) && !bytes.Equal(value, []byte(`''`))
+}
+
+// isHighEntropy reports whether s mixes upper case letters, lower case letters and digits with a Shannon entropy of
+// at least entropyThreshold bits per byte, like generated keys do and identifiers or prose do not
+func isHighEntropy(s []byte) bool {
+	if len(s) < entropyMinLength {
+		return false
+	}
+	var upper, lower, digit bool
+	var counts [256]int
+	for _, c := range s {
+		counts[c]++
+		upper = upper || (c >= 'A' && c <= 'Z')
+		lower = lower || (c >= 'a' && c <= 'z')
+		digit = digit || (c >= '0' && c <= '9')
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		if n > 0 {
+			p := float64(n) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy >= entropyThreshold
+}
@@ -0,0 +1,276 @@
+package summarize
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// renderers are the renderer of each structured Options.Format
+var renderers = map[string]renderer{
+	FormatJSON:  jsonRenderer{},
+	FormatYAML:  yamlRenderer{},
+	FormatJSONL: jsonlRenderer{},
+	FormatXML:   xmlRenderer{},
+}
+
+// newRenderer returns the renderer of the Options.Format, parsing the Options.Template over the DefaultTemplate for
+// FormatMarkdown
+func newRenderer(opts Options) (renderer, error) {
+	if opts.Format != FormatMarkdown {
+		if len(opts.Template) > 0 {
+			return nil, fmt.Errorf("a template can only render the %s format, not %s", FormatMarkdown, opts.Format)
+		}
+		return renderers[opts.Format], nil
+	}
+	tmpl, err := template.New("summary").Funcs(templateFuncs).Parse(DefaultTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing default template: %w", err)
+	}
+	if len(opts.Template) > 0 {
+		if _, err := tmpl.Parse(opts.Template); err != nil {
+			return nil, fmt.Errorf("parsing template: %w", err)
+		}
+	}
+	return templateRenderer{tmpl: tmpl}, nil
+}
+
+// header implements the renderer interface by executing the "header" template
+func (t templateRenderer) header(h header) ([]byte, error) {
+	return t.execute("header", h)
+}
+
+// file implements the renderer interface by executing the "file" template
+func (t templateRenderer) file(in Result) ([]byte, error) {
+	return t.execute("file", in)
+}
+
+// separator implements the renderer interface
+func (templateRenderer) separator() []byte {
+	return nil
+}
+
+// footer implements the renderer interface by executing the "footer" template
+func (t templateRenderer) footer(f footer) ([]byte, error) {
+	return t.execute("footer", f)
+}
+
+// execute renders the named template with data
+func (t templateRenderer) execute(name string, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// header implements the renderer interface by opening the JSON object and its files array
+func (jsonRenderer) header(h header) ([]byte, error) {
+	b, err := marshalJSON(h, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSuffix(b, []byte("\n}"))
+	return append(b, ",\n  \"files\": [\n"...), nil
+}
+
+// file implements the renderer interface with the Result as an element of the files array
+func (jsonRenderer) file(in Result) ([]byte, error) {
+	b, err := marshalJSON(in, "    ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("    "), b...), nil
+}
+
+// separator implements the renderer interface
+func (jsonRenderer) separator() []byte {
+	return []byte(",\n")
+}
+
+// footer implements the renderer interface by closing the files array and the JSON object after the footer fields
+func (jsonRenderer) footer(f footer) ([]byte, error) {
+	b, err := marshalJSON(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimPrefix(b, []byte("{"))
+	return append(append([]byte("\n  ],"), b...), '\n'), nil
+}
+
+// header implements the renderer interface with the header fields followed by the key of the files sequence
+func (yamlRenderer) header(h header) ([]byte, error) {
+	b, err := yaml.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, "files:\n"...), nil
+}
+
+// file implements the renderer interface with the Result as an item of the files sequence
+func (yamlRenderer) file(in Result) ([]byte, error) {
+	if !strings.HasPrefix(in.Contents, "\n") {
+		return yaml.Marshal([]Result{in})
+	}
+	// yaml.v3 emits contents that start with a newline as a literal block that it cannot read back, so they are
+	// double-quoted instead
+	var node yaml.Node
+	if err := node.Encode(in.File); err != nil {
+		return nil, err
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "contents"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: in.Contents, Style: yaml.DoubleQuotedStyle})
+	return yaml.Marshal([]*yaml.Node{&node})
+}
+
+// separator implements the renderer interface
+func (yamlRenderer) separator() []byte {
+	return nil
+}
+
+// footer implements the renderer interface with the footer fields
+func (yamlRenderer) footer(f footer) ([]byte, error) {
+	return yaml.Marshal(f)
+}
+
+// header implements the renderer interface with a line of the "summary" type
+func (jsonlRenderer) header(h header) ([]byte, error) {
+	return jsonLine(struct {
+		Type string `json:"type"`
+		header
+	}{Type: "summary", header: h})
+}
+
+// file implements the renderer interface with a line of the "file" type
+func (jsonlRenderer) file(in Result) ([]byte, error) {
+	return jsonLine(struct {
+		Type string `json:"type"`
+		Result
+	}{Type: "file", Result: in})
+}
+
+// separator implements the renderer interface
+func (jsonlRenderer) separator() []byte {
+	return nil
+}
+
+// footer implements the renderer interface with a line of the "report" type
+func (jsonlRenderer) footer(f footer) ([]byte, error) {
+	return jsonLine(struct {
+		Type string `json:"type"`
+		footer
+	}{Type: "report", footer: f})
+}
+
+// jsonLine marshals v on a single line that ends with a newline
+func jsonLine(v any) ([]byte, error) {
+	b, err := marshalJSON(v, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// marshalJSON marshals v like json.MarshalIndent without escaping the <, > and & that are common in source code
+func marshalJSON(v any, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// header implements the renderer interface by opening the documents element with the instructions inside of it
+func (xmlRenderer) header(h header) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<documents")
+	for _, attr := range [][2]string{
+		{"project", h.Project},
+		{"version", h.Version},
+		{"filename", h.Filename},
+		{"workspace", h.Workspace},
+	} {
+		buf.WriteString(" " + attr[0] + `="`)
+		if err := xml.EscapeText(&buf, []byte(attr[1])); err != nil {
+			return nil, err
+		}
+		buf.WriteString(`"`)
+	}
+	if h.Parts > 0 {
+		buf.WriteString(fmt.Sprintf(` part="%d" parts="%d"`, h.Part, h.Parts))
+	}
+	buf.WriteString(">\n  <instructions>")
+	if err := xml.EscapeText(&buf, []byte(h.Instructions)); err != nil {
+		return nil, err
+	}
+	buf.WriteString("</instructions>\n")
+	for _, root := range h.Roots {
+		b, err := xml.Marshal(struct {
+			XMLName xml.Name `xml:"root"`
+			Root
+		}{Root: root})
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("  ")
+		buf.Write(b)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// file implements the renderer interface with a document element whose attributes describe the File and whose
+// document_content holds the contents as CDATA
+func (xmlRenderer) file(in Result) ([]byte, error) {
+	doc := struct {
+		XMLName xml.Name `xml:"document"`
+		File
+		Content struct {
+			Text string `xml:",cdata"`
+		} `xml:"document_content"`
+	}{File: in.File}
+	doc.Content.Text = strings.Map(xmlChar, in.Contents)
+	b, err := xml.MarshalIndent(doc, "  ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// xmlChar replaces the characters that XML 1.0 does not allow, even in CDATA, with the unicode.ReplacementChar like
+// xml.EscapeText does
+func xmlChar(r rune) rune {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r',
+		r >= 0x20 && r <= 0xD7FF,
+		r >= 0xE000 && r <= 0xFFFD,
+		r >= 0x10000 && r <= 0x10FFFF:
+		return r
+	}
+	return unicode.ReplacementChar
+}
+
+// separator implements the renderer interface
+func (xmlRenderer) separator() []byte {
+	return nil
+}
+
+// footer implements the renderer interface with a report element and closes the documents element
+func (xmlRenderer) footer(f footer) ([]byte, error) {
+	b, err := xml.MarshalIndent(f, "  ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, "\n</documents>\n"...), nil
+}
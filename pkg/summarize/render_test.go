@@ -0,0 +1,94 @@
+package summarize
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestRenderParse(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"main.go":        "package main\n\nfunc main() { println(1 < 2 && 3 > 2) }\n",
+		"lead.go":        "\n\npackage lead\n",
+		"cdata.go":       "package cdata\n\nconst end = \"]]>\"\n",
+		"control.go":     "package control\n\nconst bell = \"\x07\"\n",
+		"sub/long.go":    "package long\n" + strings.Repeat("// filler\n", 12),
+		"sub/nonl.go":    "package nonl",
+		"sub/unicode.go": "package unicode\n\nconst s = \"héllo wörld ✓\"\n",
+	})
+	for _, format := range []string{FormatJSON, FormatYAML, FormatJSONL, FormatXML} {
+		t.Run(format, func(t *testing.T) {
+			opts := treeOptions(dir)
+			opts.IncludeExt, opts.Format = []string{"go"}, format
+			opts.MaxFileLines, opts.HeadLines, opts.TailLines = 10, 4, 4
+			s, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			summary, err := s.Run(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Parse(summary.Contents)
+			if err != nil {
+				t.Fatalf("parsing %s summary: %v\n%s", format, err, summary.Contents)
+			}
+			if doc.Format != format || doc.Workspace != summary.Workspace {
+				t.Errorf("Parse read a %s summary of %s, want a %s summary of %s", doc.Format, doc.Workspace, format, summary.Workspace)
+			}
+			if len(summary.Truncated) != 1 {
+				t.Fatalf("Run truncated %v, want sub/long.go", summary.Truncated)
+			}
+			if len(doc.Results) != len(summary.Results) {
+				t.Fatalf("Parse read %d files, want %d", len(doc.Results), len(summary.Results))
+			}
+			for i, want := range summary.Results {
+				got := doc.Results[i]
+				if format == FormatXML {
+					want.Contents = strings.Map(xmlChar, want.Contents)
+				}
+				if got.Contents != want.Contents {
+					t.Errorf("%s was parsed with the contents %q, want %q", want.Path, got.Contents, want.Contents)
+				}
+				if !got.ModTime.Equal(want.ModTime) {
+					t.Errorf("%s was parsed with the mtime %v, want %v", want.Path, got.ModTime, want.ModTime)
+				}
+				got.ModTime = want.ModTime
+				if !reflect.DeepEqual(got.File, want.File) {
+					t.Errorf("%s was parsed as %+v, want %+v", want.Path, got.File, want.File)
+				}
+			}
+		})
+	}
+}
+
+func TestXMLChar(t *testing.T) {
+	tests := []struct {
+		in   rune
+		want rune
+	}{
+		{'a', 'a'},
+		{'\t', '\t'},
+		{'\n', '\n'},
+		{'\r', '\r'},
+		{0x00, unicode.ReplacementChar},
+		{0x07, unicode.ReplacementChar},
+		{0x1F, unicode.ReplacementChar},
+		{0x20, 0x20},
+		{0xD7FF, 0xD7FF},
+		{0xE000, 0xE000},
+		{0xFFFD, 0xFFFD},
+		{0xFFFE, unicode.ReplacementChar},
+		{0xFFFF, unicode.ReplacementChar},
+		{0x10000, 0x10000},
+		{0x10FFFF, 0x10FFFF},
+	}
+	for _, tt := range tests {
+		if got := xmlChar(tt.in); got != tt.want {
+			t.Errorf("xmlChar(%U) = %U, want %U", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package summarize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// checkRoots verifies that the Dir of every Root is a directory and gives every Root an Alias when there is more than
+// one, refusing the aliases that are not the name of a single directory or that are given to two Roots
+func checkRoots(roots []Root) ([]Root, error) {
+	checked := make([]Root, 0, len(roots))
+	dirs := make(map[string]string)
+	for _, root := range roots {
+		if len(root.Dir) == 0 {
+			return nil, errors.New("source directory is required")
+		}
+		info, err := os.Stat(root.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("checking source directory %s: %w", root.Dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("source directory %s is not a directory", root.Dir)
+		}
+		if len(root.Alias) == 0 && len(roots) > 1 {
+			root.Alias = filepath.Base(workspace(root.Dir))
+		}
+		if len(root.Alias) > 0 {
+			if root.Alias == "." || root.Alias == ".." || strings.ContainsAny(root.Alias, `/\`) {
+				return nil, fmt.Errorf("alias %q of %s is not the name of a single directory", root.Alias, root.Dir)
+			}
+			if dir, ok := dirs[root.Alias]; ok {
+				return nil, fmt.Errorf("alias %q is given to both %s and %s", root.Alias, dir, root.Dir)
+			}
+			dirs[root.Alias] = root.Dir
+		}
+		checked = append(checked, root)
+	}
+	return checked, nil
+}
+
+// aliased reports whether the files of the roots are rendered under the Alias of their Root
+func aliased(roots []Root) bool {
+	return len(roots) > 0 && len(roots[0].Alias) > 0
+}
+
+// aliases returns the Alias of every Root in the roots, which name the files in the redactions and truncations that a
+// cacheEntry keeps
+func aliases(roots []Root) []string {
+	var names []string
+	for _, root := range roots {
+		if len(root.Alias) > 0 {
+			names = append(names, root.Alias)
+		}
+	}
+	return names
+}
+
+// workspaces returns the Workspace and the Roots that the header of a summary lists, which is the absolute path of the
+// SourceDir alone unless the Roots are aliased
+func workspaces(opts Options) (string, []Root) {
+	if !aliased(opts.Roots) {
+		return workspace(opts.SourceDir), nil
+	}
+	roots := make([]Root, len(opts.Roots))
+	for i, root := range opts.Roots {
+		roots[i] = Root{Dir: workspace(root.Dir), Alias: root.Alias}
+	}
+	return "", roots
+}
+
+// aliased returns the slash separated rel path of the Root being walked under the Alias of the Root
+func (r *run) aliased(rel string) string {
+	if len(r.root.Alias) == 0 {
+		return rel
+	}
+	return path.Join(r.root.Alias, rel)
+}
+
+// locate returns the slash separated path that the file at p was walked to relative to its Root, which is rendered as
+// its Alias/relative/path when its Root has an Alias
+func (r *run) locate(p string) string {
+	if name, ok := r.names[p]; ok {
+		return name
+	}
+	rel, err := filepath.Rel(r.root.Dir, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return r.aliased(filepath.ToSlash(rel))
+}
+
+// locate returns the Root of the path p and its slash separated path relative to that Root. A relative p starts with
+// the Alias of its Root when the Roots are aliased, and is relative to the SourceDir otherwise.
+func (s *Summarizer) locate(p string) (Root, string, error) {
+	roots := s.opts.Roots
+	if !filepath.IsAbs(p) {
+		if !aliased(roots) {
+			p = filepath.Join(roots[0].Dir, p)
+		} else {
+			alias, rest, _ := strings.Cut(path.Clean(filepath.ToSlash(p)), "/")
+			i := 0
+			for i < len(roots) && roots[i].Alias != alias {
+				i++
+			}
+			if i == len(roots) {
+				return Root{}, "", fmt.Errorf("%s does not start with the alias of a root", p)
+			}
+			p, roots = filepath.Join(roots[i].Dir, filepath.FromSlash(rest)), roots[i:i+1]
+		}
+	}
+	dirs := make([]string, 0, len(roots))
+	for _, root := range roots {
+		rel, err := filepath.Rel(workspace(root.Dir), workspace(p))
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			if rel = filepath.ToSlash(rel); rel == "." {
+				rel = ""
+			}
+			return root, rel, nil
+		}
+		dirs = append(dirs, root.Dir)
+	}
+	return Root{}, "", fmt.Errorf("%s is outside of the source directory %s", p, strings.Join(dirs, ", "))
+}
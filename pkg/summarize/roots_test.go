@@ -0,0 +1,64 @@
+package summarize
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestRoots(t *testing.T) {
+	api, protos := t.TempDir(), t.TempDir()
+	writeTree(t, api, map[string]string{
+		".gitignore":     "gen.go\n",
+		"main.go":        "package main\n",
+		"gen.go":         "package main\n",
+		"service/svc.go": "package service\n",
+	})
+	writeTree(t, protos, map[string]string{
+		"user.go":   "package protos\n",
+		"gen.go":    "package protos\n",
+		"notes.txt": "not included\n",
+	})
+	opts := treeOptions(api)
+	opts.Roots = []Root{{Dir: api, Alias: "api"}, {Dir: protos, Alias: "protos"}}
+	opts.IncludeExt, opts.GitIgnore = []string{"go"}, true
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, in := range summary.Results {
+		paths = append(paths, in.Path)
+	}
+	slices.Sort(paths)
+	// the .gitignore of the api root does not ignore the gen.go of the protos root
+	want := []string{"api/main.go", "api/service/svc.go", "protos/gen.go", "protos/user.go"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Run summarized %v, want %v", paths, want)
+	}
+	if len(summary.Roots) != 2 || summary.Roots[0].Alias != "api" || summary.Roots[1].Alias != "protos" {
+		t.Errorf("Summary.Roots = %+v, want the api and protos roots", summary.Roots)
+	}
+
+	e, err := s.Explain("api/gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Path != "api/gen.go" || e.Included || e.Rule != RuleIgnoreFile {
+		t.Errorf("Explain(api/gen.go) = %+v, want excluded by %q", e, RuleIgnoreFile)
+	}
+	if _, err := s.Explain("web/index.go"); err == nil {
+		t.Error("Explain of a path outside of the roots returned no error")
+	}
+
+	if _, err := New(Options{Roots: []Root{{Dir: api, Alias: "src"}, {Dir: protos, Alias: "src"}}}); err == nil {
+		t.Error("New with a duplicate alias returned no error")
+	}
+	if _, err := New(Options{Roots: []Root{{Dir: api, Alias: "a/b"}, {Dir: protos}}}); err == nil {
+		t.Error("New with an alias that has a separator returned no error")
+	}
+}
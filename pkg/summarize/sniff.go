@@ -0,0 +1,69 @@
+package summarize
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"unicode/utf8"
+)
+
+// generatedMarkers match the headers that code generators write at the start of a comment line in the files they
+// produce, so that source code mentioning the markers is not mistaken for generated code
+var generatedMarkers = []*regexp.Regexp{
+	regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`), // https://go.dev/s/generatedcode
+	regexp.MustCompile(`^\s*(?://|#|/?\*+|<!--|--|;)\s*@generated\b`),
+	regexp.MustCompile(`^\s*(?://|#|/?\*+|<!--|--|;)\s*<auto-generated\b`),
+	regexp.MustCompile(`(?i)^\s*(?://|#|/?\*+|<!--|--|;)\s*(?:this (?:file|code) (?:is|was) )?(?:auto-?|automatically )generated\b.*do not (?:edit|modify)`),
+}
+
+// sniff inspects the content of a file with every check enabled in the Options and returns the Omission.Reason of the
+// first check that rejects it, or an empty string when the file can be summarized
+func (r *run) sniff(content []byte) string {
+	if r.opts.SkipBinary && isBinary(content) {
+		return OmitBinary
+	}
+	if r.opts.SkipInvalidUTF8 && !utf8.Valid(content) {
+		return OmitInvalidUTF8
+	}
+	if r.opts.MaxLineLength > 0 && longestLine(content) > r.opts.MaxLineLength {
+		return OmitLongLines
+	}
+	if r.opts.SkipGenerated && isGenerated(content) {
+		return OmitGenerated
+	}
+	return ""
+}
+
+// isBinary reports whether content has a NUL byte within the first sniffLength bytes, the same heuristic git uses
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), sniffLength)], 0) >= 0
+}
+
+// longestLine returns the length in bytes of the longest line in content
+func longestLine(content []byte) int {
+	longest := 0
+	for len(content) > 0 {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			end = len(content)
+		}
+		longest = max(longest, end)
+		content = content[min(end+1, len(content)):]
+	}
+	return longest
+}
+
+// isGenerated reports whether any of the first sniffGeneratedLines lines of content carries a generated code marker
+func isGenerated(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 0; line < sniffGeneratedLines && scanner.Scan(); line++ {
+		text := bytes.TrimRight(scanner.Bytes(), "\r")
+		for _, marker := range generatedMarkers {
+			if marker.Match(text) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunSniffs(t *testing.T) {
+	binary := func(o *Options) { o.SkipBinary = true }
+	utf8 := func(o *Options) { o.SkipInvalidUTF8 = true }
+	lines := func(o *Options) { o.MaxLineLength = 20 }
+	generated := func(o *Options) { o.SkipGenerated = true }
+	tests := []struct {
+		name    string
+		content string
+		enable  func(*Options)
+		reason  string
+	}{
+		{"nul byte", "package main\x00\n", binary, OmitBinary},
+		{"nul byte past the sniff length", strings.Repeat("a", sniffLength) + "\x00", binary, ""},
+		{"nul byte without the check", "package main\x00\n", utf8, ""},
+		{"invalid utf-8", "package main\n// \xff\xfe\n", utf8, OmitInvalidUTF8},
+		{"valid utf-8", "package main\n// héllo ✓\n", utf8, ""},
+		{"line over the limit", "package main\n" + strings.Repeat("x", 21) + "\n", lines, OmitLongLines},
+		{"line at the limit", "package main\n" + strings.Repeat("x", 20) + "\n", lines, ""},
+		{"last line over the limit without a newline", "package main\n" + strings.Repeat("x", 21), lines, OmitLongLines},
+		{"go generated header", "// Code generated by stringer; DO NOT EDIT.\n\npackage main\n", generated, OmitGenerated},
+		{"@generated marker", "/*\n * @generated by protoc\n */\npackage main\n", generated, OmitGenerated},
+		{"auto-generated marker", "# This file is auto-generated, do not edit\nkey: value\n", generated, OmitGenerated},
+		{"<auto-generated marker", "// <auto-generated>\npackage main\n", generated, OmitGenerated},
+		{"marker in a string", "package main\n\nvar s = \"// Code generated by x; DO NOT EDIT.\"\n", generated, ""},
+		{"marker past the sniffed lines", strings.Repeat("\n", sniffGeneratedLines) + "// Code generated by x; DO NOT EDIT.\n", generated, ""},
+		{"generated without the check", "// Code generated by stringer; DO NOT EDIT.\n\npackage main\n", binary, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTree(t, dir, map[string]string{"file.go": tt.content, "keep.go": "package main\n"})
+			opts := treeOptions(dir)
+			opts.IncludeExt = []string{"go"}
+			tt.enable(&opts)
+			s, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			summary, err := s.Run(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reason string
+			for _, o := range summary.Omitted {
+				if o.Path == "file.go" {
+					reason = o.Reason
+				}
+			}
+			if reason != tt.reason {
+				t.Errorf("file.go was omitted for %q, want %q", reason, tt.reason)
+			}
+			if len(summary.Results)+len(summary.Omitted) != 2 {
+				t.Errorf("Run summarized %d and omitted %d of 2 files", len(summary.Results), len(summary.Omitted))
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package summarize walks a source directory and renders the matched files into a single Markdown summary that can
+// be handed to an AI as the context of a project workspace.
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/andreimerlescu/sema"
+)
+
+// New returns a Summarizer for the opts after applying the defaults and verifying the opts.SourceDir is a directory.
+// Options that are refused return an Error of KindConfig.
+func New(opts Options) (s *Summarizer, err error) {
+	defer func() {
+		if err != nil {
+			s, err = nil, &Error{Kind: KindConfig, Err: err}
+		}
+	}()
+	if len(opts.Roots) == 0 {
+		opts.Roots = []Root{{Dir: opts.SourceDir}}
+	}
+	if opts.Roots, err = checkRoots(opts.Roots); err != nil {
+		return nil, err
+	}
+	opts.SourceDir = opts.Roots[0].Dir
+	if opts.IncludeExt == nil {
+		opts.IncludeExt = DefaultIncludeExt
+	}
+	if opts.ExcludeExt == nil {
+		opts.ExcludeExt = DefaultExcludeExt
+	}
+	if opts.SkipContains == nil {
+		opts.SkipContains = DefaultSkipContains
+	}
+	if opts.MaxOutputSize <= 0 {
+		opts.MaxOutputSize = DefaultMaxOutputSize
+	}
+	if opts.MaxFileBytes < 0 || opts.MaxFileLines < 0 || opts.HeadLines < 0 || opts.TailLines < 0 {
+		return nil, errors.New("max file bytes, max file lines, head lines and tail lines cannot be negative")
+	}
+	if opts.HeadLines == 0 && opts.TailLines == 0 {
+		opts.HeadLines, opts.TailLines = DefaultHeadLines, DefaultTailLines
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultMaxFiles
+	}
+	if len(opts.Filename) == 0 {
+		opts.Filename = filepath.Base(opts.SourceDir)
+	}
+	switch opts.Git {
+	case "", GitModeTracked, GitModeStaged:
+	case GitModeChanged:
+		if len(opts.GitSince) == 0 {
+			opts.GitSince = "HEAD"
+		}
+	default:
+		return nil, fmt.Errorf("unknown git mode %q, expected %s, %s or %s", opts.Git, GitModeTracked, GitModeChanged, GitModeStaged)
+	}
+	if opts.Tokenizer == nil {
+		opts.Tokenizer = BPETokenizer{}
+	}
+	if opts.MaxTokens < 0 {
+		return nil, fmt.Errorf("max tokens %d cannot be negative", opts.MaxTokens)
+	}
+	if opts.DebugWriter == nil {
+		opts.DebugWriter = os.Stdout
+	}
+	switch opts.Order {
+	case "":
+		opts.Order = OrderPath
+	case OrderPath, OrderTree, OrderSize, OrderModified, OrderPriority:
+	default:
+		return nil, fmt.Errorf("unknown order %q, expected %s, %s, %s, %s or %s", opts.Order, OrderPath, OrderTree, OrderSize, OrderModified, OrderPriority)
+	}
+	switch opts.Dots {
+	case "":
+		opts.Dots = DotsInclude
+	case DotsInclude, DotsExcludeFiles, DotsExcludeDirs, DotsExclude:
+	default:
+		return nil, fmt.Errorf("unknown dots policy %q, expected %s, %s, %s or %s", opts.Dots, DotsInclude, DotsExcludeFiles, DotsExcludeDirs, DotsExclude)
+	}
+	allow := make([]string, 0, len(opts.DotAllow))
+	for _, p := range opts.DotAllow {
+		if p = strings.Trim(path.Clean(filepath.ToSlash(p)), "/"); len(p) > 0 && p != "." {
+			allow = append(allow, p)
+		}
+	}
+	opts.DotAllow = allow
+	switch opts.Format {
+	case "":
+		opts.Format = FormatMarkdown
+	case FormatMarkdown, FormatJSON, FormatYAML, FormatJSONL, FormatXML:
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s, %s, %s, %s or %s", opts.Format, FormatMarkdown, FormatJSON, FormatYAML, FormatJSONL, FormatXML)
+	}
+	if opts.EntryPoints == nil {
+		opts.EntryPoints = DefaultEntryPoints
+	}
+	if opts.Demote == nil {
+		opts.Demote = DefaultDemote
+	}
+	s = &Summarizer{}
+	if s.renderer, err = newRenderer(opts); err != nil {
+		return nil, err
+	}
+	if s.priority, err = compileGlobs("priority", opts.Priority); err != nil {
+		return nil, err
+	}
+	if s.entryPoints, err = compileGlobs("entry point", opts.EntryPoints); err != nil {
+		return nil, err
+	}
+	if s.demote, err = compileGlobs("demote", opts.Demote); err != nil {
+		return nil, err
+	}
+	if s.filters, err = compileFilters(opts); err != nil {
+		return nil, err
+	}
+	opts.IncludeExt = simplify(opts.IncludeExt)
+	opts.ExcludeExt = simplify(opts.ExcludeExt)
+	opts.SkipContains = simplify(opts.SkipContains)
+	s.opts = opts
+	return s, nil
+}
+
+// Options returns a copy of the Options the Summarizer was created with after the defaults were applied
+func (s *Summarizer) Options() Options {
+	return s.opts
+}
+
+// Run walks the SourceDir and renders every matched file into a Summary. When individual files fail to be read, the
+// Summary of the remaining files is still returned alongside the joined errors, which are an Error with the Kind of
+// step that failed and are listed in the Summary.Errors and the footer of the summary. When Options.FailOnSecret finds
+// a secret, no Summary is returned and the error wraps ErrSecretFound.
+func (s *Summarizer) Run(ctx context.Context) (*Summary, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	r := &run{
+		ctx:              ctx,
+		cancel:           cancel,
+		opts:             s.opts,
+		renderer:         s.renderer,
+		priority:         s.priority,
+		entryPoints:      s.entryPoints,
+		demote:           s.demote,
+		filters:          s.filters,
+		data:             &sync.Map{},
+		seen:             &seenStrings{m: make(map[string]bool)},
+		names:            make(map[string]string),
+		errs:             &collector{},
+		results:          make(chan Result, s.opts.MaxFiles),
+		wg:               &sync.WaitGroup{},
+		writerWG:         &sync.WaitGroup{},
+		throttler:        sema.New(runtime.GOMAXPROCS(0)),
+		maxFileSemaphore: sema.New(s.opts.MaxFiles),
+	}
+	if len(s.opts.CacheFile) > 0 {
+		c, err := loadCache(s.opts.CacheFile, s.opts)
+		if err != nil {
+			// a cache that cannot be read is rebuilt from scratch rather than failing the run
+			r.debugf("cache: %v\n", err)
+			c = &cache{Fingerprint: cacheFingerprint(s.opts), Entries: make(map[string]cacheEntry)}
+		}
+		r.cache = c
+	}
+	for _, i := range s.opts.IncludeExt {
+		r.data.Store(i, mapData{
+			Ext:   i,
+			Paths: []string{},
+		})
+	}
+
+	// populate data with the SourceDir files based on the include, exclude and skip lists
+	if err := r.walk(); err != nil {
+		return nil, err
+	}
+	if s.opts.Debug {
+		r.debugData()
+	}
+
+	r.writerWG.Add(1)
+	go r.receive()
+
+	r.data.Range(r.iterate)
+
+	r.wg.Wait() // wait for all files to finish processing
+
+	for _, innerData := range r.toUpdate {
+		r.data.Store(innerData.Ext, innerData)
+	}
+
+	close(r.results)  // Signal the writer goroutine to finish
+	r.writerWG.Wait() // Wait for the writer to flush the summary
+
+	if r.cache != nil && ctx.Err() == nil {
+		r.debugf("cache: reused %d files from %s\n", r.cacheHits, s.opts.CacheFile)
+		if err := r.cache.save(s.opts.CacheFile); err != nil {
+			r.errs.Add(&Error{Kind: KindRender, Path: s.opts.CacheFile, Err: err})
+		}
+	}
+
+	summary := &Summary{
+		Results:    r.rendered,
+		Contents:   r.buf.Bytes(),
+		Size:       int64(r.buf.Len()),
+		Tokens:     r.tokens,
+		Omitted:    r.omitted,
+		Redactions: r.redactions,
+		Truncated:  r.truncated,
+		Errors:     r.errs.FileErrors(),
+		Chunks:     r.chunks,
+	}
+	for _, c := range r.chunks {
+		summary.Size += c.Size // the Contents stay empty while the Chunks hold the parts
+	}
+	summary.Workspace, summary.Roots = workspaces(s.opts)
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return summary, r.errs.Err()
+}
+
+// workspace returns the absolute path of the srcDir or the srcDir itself when it cannot be resolved
+func workspace(srcDir string) string {
+	abs, err := filepath.Abs(srcDir)
+	if err != nil {
+		return srcDir
+	}
+	return abs
+}
@@ -0,0 +1,81 @@
+package summarize
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplate is the text/template of the FormatMarkdown summary. An Options.Template is parsed over it, so a
+// template only needs to define the "header", "file" or "footer" templates that it changes.
+//
+//go:embed summary.tmpl
+var DefaultTemplate string
+
+// templateFuncs are the helpers available to the DefaultTemplate and every Options.Template
+var templateFuncs = template.FuncMap{
+	"base":       filepath.Base,
+	"dir":        filepath.Dir,
+	"ext":        func(path string) string { return strings.TrimPrefix(filepath.Ext(path), ".") },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"stat":       statJSON,
+	"json":       indentJSON,
+	"fence":      fence,
+	"numbered":   numbered,
+	"language":   language,
+	"lines":      func(s string) int { return strings.Count(strings.TrimSuffix(s, "\n"), "\n") + 1 },
+}
+
+// statJSON renders the name, size and mode of the File as the indented JSON of its os.Stat
+func statJSON(f File) (string, error) {
+	type tFileInfo struct {
+		Name string      `json:"name"`
+		Size int64       `json:"size"`
+		Mode os.FileMode `json:"mode"`
+	}
+	return indentJSON(&tFileInfo{Name: filepath.Base(f.Path), Size: f.Size, Mode: f.Mode})
+}
+
+// indentJSON renders v as JSON indented by two spaces
+func indentJSON(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	return string(b), err
+}
+
+// fence wraps contents in a code block of the lang whose fence is longer than any run of backticks in the contents
+func fence(lang, contents string) string {
+	longest, run := 0, 0
+	for _, c := range contents {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	ticks := strings.Repeat("`", max(3, longest+1))
+	return ticks + lang + "\n" + strings.TrimSuffix(contents, "\n") + "\n" + ticks
+}
+
+// numbered prefixes every line of contents with its line number
+func numbered(contents string) string {
+	lines := strings.Split(strings.TrimSuffix(contents, "\n"), "\n")
+	width := len(fmt.Sprint(len(lines)))
+	var sb strings.Builder
+	for i, line := range lines {
+		sb.WriteString(fmt.Sprintf("%*d | %s\n", width, i+1, line))
+	}
+	return sb.String()
+}
+
+// language returns the name of the programming language of the extension, or the extension when it is not known
+func language(ext string) string {
+	if name, ok := languages[strings.ToLower(strings.TrimPrefix(ext, "."))]; ok {
+		return name
+	}
+	return ext
+}
@@ -0,0 +1,174 @@
+package summarize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	tokenizersMu sync.RWMutex
+
+	// tokenizers are the Tokenizer implementations available to LookupTokenizer by name
+	tokenizers = map[string]Tokenizer{
+		TokenizerBPE:   BPETokenizer{},
+		TokenizerChars: CharTokenizer{CharsPerToken: DefaultCharsPerToken},
+	}
+)
+
+// RegisterTokenizer makes the Tokenizer t available to LookupTokenizer under name, replacing any existing one
+func RegisterTokenizer(name string, t Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+	tokenizers[name] = t
+}
+
+// LookupTokenizer returns the Tokenizer registered under name
+func LookupTokenizer(name string) (Tokenizer, error) {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+	t, ok := tokenizers[name]
+	if !ok {
+		names := make([]string, 0, len(tokenizers))
+		for n := range tokenizers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown tokenizer %q, expected one of %v", name, names)
+	}
+	return t, nil
+}
+
+// Name implements the Tokenizer interface
+func (BPETokenizer) Name() string {
+	return TokenizerBPE
+}
+
+// Count implements the Tokenizer interface by splitting text the way byte pair encoders pre-tokenize it (words and
+// punctuation with their leading space, numbers and whitespace) and charging each piece by the length BPE vocabularies
+// typically merge into a single token
+func (BPETokenizer) Count(text []byte) int {
+	tokens := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			tokens++ // every invalid byte becomes its own byte token
+			i++
+		case r == '\n' || r == '\r':
+			for i < len(text) && (text[i] == '\n' || text[i] == '\r') {
+				i++
+			}
+			tokens++
+		case unicode.IsSpace(r):
+			start := i
+			for i < len(text) && (text[i] == ' ' || text[i] == '\t') {
+				i++
+			}
+			if i == start {
+				i += size
+			}
+			if i < len(text) && i-start == 1 && (isWordByte(text[i]) || isPunctByte(text[i])) {
+				continue // a single space is merged into the word or punctuation that follows it
+			}
+			tokens += ceilDiv(i-start, 8)
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+				i++
+			}
+			if i == start {
+				i += size
+			}
+			tokens += ceilDiv(i-start, 3)
+		case r < utf8.RuneSelf && isWordByte(byte(r)):
+			start := i
+			for i < len(text) && isWordByte(text[i]) {
+				i++
+			}
+			tokens += countWordTokens(text[start:i])
+		case r >= utf8.RuneSelf && unicode.IsLetter(r):
+			for i < len(text) {
+				r, size = utf8.DecodeRune(text[i:])
+				if r < utf8.RuneSelf || !unicode.IsLetter(r) {
+					break
+				}
+				i += size
+				tokens++ // multibyte letters rarely merge with their neighbours
+			}
+		default:
+			start := i
+			for i < len(text) && text[i] < utf8.RuneSelf && isPunctByte(text[i]) {
+				i++
+			}
+			if i == start {
+				i += size
+				tokens++
+				continue
+			}
+			tokens += ceilDiv(i-start, 2)
+		}
+	}
+	return tokens
+}
+
+// countWordTokens charges an ASCII word one token per camelCase or snake_case part, plus one per 6 letters of any
+// part that is longer than a typical vocabulary entry
+func countWordTokens(word []byte) int {
+	tokens, part := 0, 0
+	flush := func() {
+		if part > 0 {
+			tokens += ceilDiv(part, 6)
+		}
+		part = 0
+	}
+	for i, c := range word {
+		switch {
+		case c == '_':
+			flush()
+			tokens++
+		case c >= '0' && c <= '9':
+			flush()
+			tokens++
+		case c >= 'A' && c <= 'Z' && i > 0 && word[i-1] >= 'a' && word[i-1] <= 'z':
+			flush()
+			part++
+		default:
+			part++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isWordByte reports whether c is an ASCII letter, digit or underscore
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// isPunctByte reports whether c is printable ASCII that is neither a word byte nor whitespace
+func isPunctByte(c byte) bool {
+	return c > ' ' && c < 0x7f && !isWordByte(c)
+}
+
+// ceilDiv returns n divided by d rounded up
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
+
+// Name implements the Tokenizer interface
+func (CharTokenizer) Name() string {
+	return TokenizerChars
+}
+
+// Count implements the Tokenizer interface by dividing the number of characters in text by CharsPerToken
+func (t CharTokenizer) Count(text []byte) int {
+	ratio := t.CharsPerToken
+	if ratio <= 0 {
+		ratio = DefaultCharsPerToken
+	}
+	return int(math.Ceil(float64(utf8.RuneCount(text)) / ratio))
+}
@@ -0,0 +1,88 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTokenizers(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		bpe   int
+		chars int
+	}{
+		{"empty", "", 0, 0},
+		{"words merge their leading space", "hello world", 2, 3},
+		{"camelCase parts", "getUserName", 3, 3},
+		{"snake_case parts", "snake_case_name", 5, 4},
+		{"long word", "internationalization", 4, 5},
+		{"digits in threes", "12345678", 3, 2},
+		{"runs of newlines", "a\n\n\nb", 3, 2},
+		{"indentation", "        x", 2, 3},
+		{"punctuation in pairs", "a != b", 3, 2},
+		{"multibyte letters", "héllo", 3, 2},
+		{"invalid bytes", "\xff\xfe", 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (BPETokenizer{}).Count([]byte(tt.text)); got != tt.bpe {
+				t.Errorf("BPETokenizer counts %q as %d tokens, want %d", tt.text, got, tt.bpe)
+			}
+			if got := (CharTokenizer{}).Count([]byte(tt.text)); got != tt.chars {
+				t.Errorf("CharTokenizer counts %q as %d tokens, want %d", tt.text, got, tt.chars)
+			}
+		})
+	}
+	if got := (CharTokenizer{CharsPerToken: 2}).Count([]byte("hello")); got != 3 {
+		t.Errorf("CharTokenizer with 2 characters per token counts hello as %d tokens, want 3", got)
+	}
+	// source code is dense in punctuation and short identifiers, which BPE charges more than 4 characters per token
+	code := []byte("func (r *run) order(results []Result) {\n\tif len(results) == 0 {\n\t\treturn\n\t}\n}\n")
+	if bpe, chars := (BPETokenizer{}).Count(code), (CharTokenizer{}).Count(code); bpe <= chars {
+		t.Errorf("BPETokenizer counts %d tokens in code that CharTokenizer counts as %d, want more", bpe, chars)
+	}
+}
+
+func TestRunMaxTokens(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package b\n\n" + strings.Repeat("var x = map[string]int{\"one\": 1, \"two\": 2}\n", 100),
+		"c.go": "package c\n",
+	})
+	opts := treeOptions(dir)
+	opts.IncludeExt = []string{"go"}
+	// the header lists the files so that it changes when a file is dropped
+	opts.Template = "{{define \"header\"}}files:{{range .Files}} {{.Path}}{{end}}\n{{end}}"
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(all.Contents), "files: a.go b.go c.go\n") || len(all.Omitted) > 0 {
+		t.Fatalf("Run without a token budget rendered %q and omitted %v", strings.SplitN(string(all.Contents), "\n", 2)[0], all.Omitted)
+	}
+
+	opts.MaxTokens = all.Tokens / 2
+	if s, err = New(opts); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Omitted) != 1 || summary.Omitted[0].Path != "b.go" || summary.Omitted[0].Reason != OmitTokenBudget {
+		t.Errorf("Run with %d of %d tokens omitted %+v, want b.go over the token budget", opts.MaxTokens, all.Tokens, summary.Omitted)
+	}
+	if !strings.HasPrefix(string(summary.Contents), "files: a.go c.go\n") {
+		t.Errorf("the header was rendered as %q, want it without b.go", strings.SplitN(string(summary.Contents), "\n", 2)[0])
+	}
+	if summary.Tokens > opts.MaxTokens || len(summary.Results) != 2 {
+		t.Errorf("Run rendered %d files in %d tokens, want a.go and c.go within %d", len(summary.Results), summary.Tokens, opts.MaxTokens)
+	}
+}
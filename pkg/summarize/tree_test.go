@@ -0,0 +1,27 @@
+package summarize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree writes the contents of the files at their slash separated paths inside dir, creating their directories
+func writeTree(tb testing.TB, dir string, files map[string]string) {
+	tb.Helper()
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// treeOptions returns the Options of a summary of the dir that is skipped by the skip substrings alone. The
+// DefaultSkipContains would skip every path of a t.TempDir, since they contain /tmp.
+func treeOptions(dir string, skip ...string) Options {
+	return Options{SourceDir: dir, SkipContains: append([]string{}, skip...)}
+}
@@ -0,0 +1,64 @@
+package summarize
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// truncate keeps the first Options.HeadLines and the last Options.TailLines of content when it is over the
+// Options.MaxFileLines or Options.MaxFileBytes, joining them with a marker of the elided lines. Lines are dropped from
+// the longer end until the kept lines fit within the Options.MaxFileBytes as well.
+func (r *run) truncate(path string, content []byte) ([]byte, *Truncation) {
+	overBytes := r.opts.MaxFileBytes > 0 && int64(len(content)) > r.opts.MaxFileBytes
+	if !overBytes && r.opts.MaxFileLines == 0 {
+		return content, nil
+	}
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	overLines := r.opts.MaxFileLines > 0 && len(lines) > r.opts.MaxFileLines
+	if !overBytes && !overLines {
+		return content, nil
+	}
+	head, tail := r.opts.HeadLines, r.opts.TailLines
+	if r.opts.MaxFileLines > 0 && head+tail > r.opts.MaxFileLines {
+		head = r.opts.MaxFileLines * head / (head + tail)
+		tail = r.opts.MaxFileLines - head
+	}
+	head, tail = min(head, len(lines)), min(tail, len(lines)-min(head, len(lines)))
+	kept := 0
+	for _, line := range lines[:head] {
+		kept += len(line)
+	}
+	for _, line := range lines[len(lines)-tail:] {
+		kept += len(line)
+	}
+	for r.opts.MaxFileBytes > 0 && int64(kept) > r.opts.MaxFileBytes && head+tail > 0 {
+		if head > tail {
+			head--
+			kept -= len(lines[head])
+		} else {
+			kept -= len(lines[len(lines)-tail])
+			tail--
+		}
+	}
+	elided := len(lines) - head - tail
+	if elided == 0 {
+		return content, nil
+	}
+	var out bytes.Buffer
+	for _, line := range lines[:head] {
+		out.Write(line)
+	}
+	if head > 0 && !bytes.HasSuffix(lines[head-1], []byte("\n")) {
+		out.WriteString("\n")
+	}
+	out.WriteString(fmt.Sprintf("[... %d of %d lines (%d bytes) elided by summarize ...]\n", elided, len(lines), len(content)-kept))
+	for _, line := range lines[len(lines)-tail:] {
+		out.Write(line)
+	}
+	t := &Truncation{Path: path, Lines: len(lines), Size: int64(len(content)), Head: head, Tail: tail, Elided: elided}
+	r.debugf("truncated %s: kept %d head and %d tail of %d lines\n", path, head, tail, len(lines))
+	return out.Bytes(), t
+}
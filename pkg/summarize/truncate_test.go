@@ -0,0 +1,64 @@
+package summarize
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	numbered := func(n int) string {
+		var sb strings.Builder
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(&sb, "line %d\n", i)
+		}
+		return sb.String()
+	}
+	marker := func(elided, lines, size int) string {
+		return fmt.Sprintf("[... %d of %d lines (%d bytes) elided by summarize ...]\n", elided, lines, size)
+	}
+	tests := []struct {
+		name                    string
+		content                 string
+		maxLines                int
+		maxBytes                int64
+		head, tail              int
+		want                    string
+		wantHead, wantTail, cut int
+	}{
+		{"head and tail cover every line", numbered(8), 10, 0, 20, 20, numbered(8), 0, 0, 0},
+		{"head and tail over the line limit", numbered(12), 10, 0, 20, 20,
+			"line 1\nline 2\nline 3\nline 4\nline 5\n" + marker(2, 12, 14) + "line 8\nline 9\nline 10\nline 11\nline 12\n", 5, 5, 2},
+		{"lines", numbered(10), 4, 0, 2, 2,
+			"line 1\nline 2\n" + marker(6, 10, 42) + "line 9\nline 10\n", 2, 2, 6},
+		{"under the bytes", numbered(3), 0, 100, 2, 2, numbered(3), 0, 0, 0},
+		{"bytes only", numbered(9), 0, 30, 2, 2,
+			"line 1\nline 2\n" + marker(5, 9, 35) + "line 8\nline 9\n", 2, 2, 5},
+		{"bytes drop lines from the longer end", numbered(9), 0, 20, 2, 2,
+			"line 1\n" + marker(7, 9, 49) + "line 9\n", 1, 1, 7},
+		{"no trailing newline", "a\nb\nc\nd\ne", 2, 0, 1, 1, "a\n" + marker(3, 5, 6) + "e", 1, 1, 3},
+		{"single line without a newline over the bytes", strings.Repeat("x", 40), 0, 10, 2, 2, marker(1, 1, 40), 0, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &run{opts: Options{MaxFileLines: tt.maxLines, MaxFileBytes: tt.maxBytes, HeadLines: tt.head, TailLines: tt.tail}}
+			out, truncation := r.truncate("file.go", []byte(tt.content))
+			if string(out) != tt.want {
+				t.Errorf("truncate returned %q, want %q", out, tt.want)
+			}
+			if tt.cut == 0 {
+				if truncation != nil {
+					t.Errorf("truncate elided %+v, want nothing", truncation)
+				}
+				return
+			}
+			if truncation == nil {
+				t.Fatal("truncate returned no Truncation")
+			}
+			if truncation.Head != tt.wantHead || truncation.Tail != tt.wantTail || truncation.Elided != tt.cut ||
+				truncation.Size != int64(len(tt.content)) || truncation.Path != "file.go" {
+				t.Errorf("truncate returned %+v, want %d head, %d tail and %d elided of %d bytes", truncation, tt.wantHead, tt.wantTail, tt.cut, len(tt.content))
+			}
+		})
+	}
+}
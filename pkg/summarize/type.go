@@ -0,0 +1,592 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/andreimerlescu/sema"
+)
+
+type (
+
+	// Options configures a Summarizer. SourceDir or Roots is required; every other field falls back to the package
+	// defaults.
+	Options struct {
+		// SourceDir is the directory path to generate a summary of
+		SourceDir string `yaml:"source_dir" json:"source_dir"`
+
+		// Roots are the directories to generate a single summary of instead of the SourceDir, which becomes the Dir of
+		// the first Root. When there is more than one, every Root has an Alias that defaults to the name of its Dir.
+		Roots []Root `yaml:"roots" json:"roots"`
+
+		// Filename is the name of the summary that is rendered in the header
+		Filename string `yaml:"filename" json:"filename"`
+
+		// Version is the version of the caller that is rendered in the header
+		Version string `yaml:"version" json:"version"`
+
+		// IncludeExt are the extensions to summarize
+		IncludeExt []string `yaml:"include_ext" json:"include_ext"`
+
+		// ExcludeExt are the extensions NOT to summarize
+		ExcludeExt []string `yaml:"exclude_ext" json:"exclude_ext"`
+
+		// SkipContains are the substrings in the paths to ignore
+		SkipContains []string `yaml:"skip_contains" json:"skip_contains"`
+
+		// Dots is the policy for the files and directories whose name starts with a ".": DotsInclude (the default),
+		// DotsExcludeFiles, DotsExcludeDirs or DotsExclude. Excluded directories are not walked at all.
+		Dots string `yaml:"dots" json:"dots"`
+
+		// DotAllow are the paths relative to the SourceDir, such as ".github/workflows", that are summarized even when
+		// the Dots policy excludes them, along with everything inside of them
+		DotAllow []string `yaml:"dot_allow" json:"dot_allow"`
+
+		// IncludeGlob are the globs relative to the SourceDir, such as "cmd/**/*.go", of the only files that are
+		// summarized when any are given
+		IncludeGlob []string `yaml:"include_glob" json:"include_glob"`
+
+		// ExcludeGlob are the globs relative to the SourceDir of the paths to skip, in the syntax of a .gitignore where
+		// the last matching glob wins and a "!" glob includes the paths again, like "*_test.go,!internal/**"
+		ExcludeGlob []string `yaml:"exclude_glob" json:"exclude_glob"`
+
+		// ExcludeRegex are the regular expressions of the slash separated paths relative to the SourceDir to skip
+		ExcludeRegex []string `yaml:"exclude_regex" json:"exclude_regex"`
+
+		// SkipBinary skips files with a NUL byte in their first bytes, catching binaries that have no telling extension
+		SkipBinary bool `yaml:"skip_binary" json:"skip_binary"`
+
+		// SkipInvalidUTF8 skips files whose contents are not valid UTF-8
+		SkipInvalidUTF8 bool `yaml:"skip_invalid_utf8" json:"skip_invalid_utf8"`
+
+		// MaxLineLength skips files with a line longer than this many bytes, such as minified bundles, 0 is unlimited
+		MaxLineLength int `yaml:"max_line_length" json:"max_line_length"`
+
+		// SkipGenerated skips files carrying a generated code marker such as "// Code generated ... DO NOT EDIT."
+		SkipGenerated bool `yaml:"skip_generated" json:"skip_generated"`
+
+		// Redact replaces the secrets found in every file with a "[REDACTED:kind]" placeholder before it is rendered and
+		// lists them in the Summary.Redactions
+		Redact bool `yaml:"redact" json:"redact"`
+
+		// FailOnSecret aborts the Run with ErrSecretFound as soon as a secret is found in any file
+		FailOnSecret bool `yaml:"fail_on_secret" json:"fail_on_secret"`
+
+		// GitIgnore honors the .gitignore file of every directory inside the SourceDir
+		GitIgnore bool `yaml:"git_ignore" json:"git_ignore"`
+
+		// DockerIgnore honors the .dockerignore file in the root of the SourceDir
+		DockerIgnore bool `yaml:"docker_ignore" json:"docker_ignore"`
+
+		// SummarizeIgnore honors the .summarizeignore file in the root of the SourceDir
+		SummarizeIgnore bool `yaml:"summarize_ignore" json:"summarize_ignore"`
+
+		// Git selects the files from the git repository of the SourceDir instead of walking it, as GitModeTracked,
+		// GitModeChanged or GitModeStaged. Only the files in the git index are selected, so untracked files are left
+		// out by every mode. The selected files still pass through every other filter.
+		Git string `yaml:"git" json:"git"`
+
+		// GitSince is the revision that GitModeChanged compares the worktree against, HEAD when empty
+		GitSince string `yaml:"git_since" json:"git_since"`
+
+		// GitMergeBase compares the worktree against the merge-base of GitSince and HEAD in GitModeChanged
+		GitMergeBase bool `yaml:"git_merge_base" json:"git_merge_base"`
+
+		// MaxFileBytes truncates every file larger than this many bytes to its HeadLines and TailLines, 0 is unlimited
+		MaxFileBytes int64 `yaml:"max_file_bytes" json:"max_file_bytes"`
+
+		// MaxFileLines truncates every file with more than this many lines to its HeadLines and TailLines, 0 is unlimited
+		MaxFileLines int `yaml:"max_file_lines" json:"max_file_lines"`
+
+		// HeadLines is how many lines from the start of a truncated file are kept, DefaultHeadLines when both HeadLines
+		// and TailLines are 0
+		HeadLines int `yaml:"head_lines" json:"head_lines"`
+
+		// TailLines is how many lines from the end of a truncated file are kept, DefaultTailLines when both HeadLines
+		// and TailLines are 0
+		TailLines int `yaml:"tail_lines" json:"tail_lines"`
+
+		// MaxOutputSize stops summarizing the SourceDir once the summary reaches this size in bytes
+		MaxOutputSize int64 `yaml:"max_output_size" json:"max_output_size"`
+
+		// Order is the order the files are rendered in: OrderPath (default), OrderTree, OrderSize, OrderModified or
+		// OrderPriority
+		Order string `yaml:"order" json:"order"`
+
+		// Priority are the globs whose matching files are rendered first, in the order of the globs, by OrderPriority
+		Priority []string `yaml:"priority" json:"priority"`
+
+		// Pack ranks every file before rendering and fills the MaxOutputSize and MaxTokens budgets greedily from the top
+		// of the ranking instead of in the Order
+		Pack bool `yaml:"pack" json:"pack"`
+
+		// EntryPoints are the globs boosted by Pack, DefaultEntryPoints when nil
+		EntryPoints []string `yaml:"entry_points" json:"entry_points"`
+
+		// Demote are the globs demoted by Pack, DefaultDemote when nil
+		Demote []string `yaml:"demote" json:"demote"`
+
+		// MaxTokens stops adding files to the summary once the next file would push it past this many tokens, 0 is unlimited
+		MaxTokens int `yaml:"max_tokens" json:"max_tokens"`
+
+		// Tokenizer estimates the tokens of every file and the summary, a BPETokenizer when nil
+		Tokenizer Tokenizer `yaml:"-" json:"-"`
+
+		// Template is a text/template that is parsed over the DefaultTemplate to render FormatMarkdown. It can define a
+		// "header" template of the header fields and the Files that are rendered, a "file" template of each Result, and
+		// a "footer" template of the Truncated, Redactions and Omitted files.
+		Template string `yaml:"template" json:"template"`
+
+		// Chunk splits the summary into Summary.Chunks that each stay under the MaxOutputSize and MaxTokens instead of
+		// leaving out the files over those budgets, splitting a file across chunks only when it is over them by itself
+		Chunk bool `yaml:"chunk" json:"chunk"`
+
+		// Format is the format the summary is rendered in: FormatMarkdown (default), FormatJSON, FormatYAML, FormatJSONL or
+		// FormatXML
+		Format string `yaml:"format" json:"format"`
+
+		// CacheFile is the path of a cache of every file that was summarized, keyed by its path, size, modification time
+		// and hash, whose contents, redactions and tokens are reused by the next run instead of reading the file again.
+		// The cache is disabled when empty.
+		CacheFile string `yaml:"cache_file" json:"cache_file"`
+
+		// MaxFiles is the maximum number of files that will concurrently be summarized
+		MaxFiles int `yaml:"max_files" json:"max_files"`
+
+		// Debug renders additional log statements to DebugWriter
+		Debug bool `yaml:"debug" json:"debug"`
+
+		// DebugWriter receives the Debug log statements, os.Stdout when nil
+		DebugWriter io.Writer `yaml:"-" json:"-"`
+	}
+
+	// Tokenizer estimates the number of tokens a model will use for text. Implementations must be safe for
+	// concurrent use.
+	Tokenizer interface {
+		Name() string
+		Count(text []byte) int
+	}
+
+	// BPETokenizer approximates the byte pair encodings used by most LLMs without loading a vocabulary
+	BPETokenizer struct{}
+
+	// CharTokenizer estimates one token for every CharsPerToken characters
+	CharTokenizer struct {
+		CharsPerToken float64
+	}
+
+	// Root is a directory of Options.Roots whose files are rendered as Alias/relative/path, or relative to the Dir
+	// alone when the Alias is empty. Every Root is walked with its own ignore files and path filters.
+	Root struct {
+		Dir   string `yaml:"dir" json:"dir" xml:"dir,attr"`
+		Alias string `yaml:"alias,omitempty" json:"alias,omitempty" xml:"alias,attr,omitempty"`
+	}
+
+	// Summarizer walks the Options.SourceDir and renders the matched files into a Summary
+	Summarizer struct {
+		opts        Options
+		renderer    renderer
+		priority    []*regexp.Regexp
+		entryPoints []*regexp.Regexp
+		demote      []*regexp.Regexp
+		filters
+	}
+
+	// filters are the compiled Options.IncludeGlob, Options.ExcludeGlob and Options.ExcludeRegex
+	filters struct {
+		includeGlob  []*regexp.Regexp
+		excludeGlob  *ignoreFile
+		excludeRegex []*regexp.Regexp
+	}
+
+	// Explanation is the rule of the Options that includes or excludes a path, as returned by Summarizer.Explain
+	Explanation struct {
+		// Path is the slash separated path relative to the SourceDir that was explained
+		Path string `yaml:"path" json:"path"`
+
+		// Included is whether the Path is summarized, unless it is skipped once its contents are read
+		Included bool `yaml:"included" json:"included"`
+
+		// Rule is the RuleSkip, RuleDots, RuleIgnoreFile, RuleExcludeRegex, RuleExcludeGlob, RuleIncludeGlob,
+		// RuleExcludeExt or RuleIncludeExt that decided, or empty for a directory that is walked
+		Rule string `yaml:"rule" json:"rule"`
+
+		// Match is the substring, glob, regular expression, ignore file rule or type that the Rule matched
+		Match string `yaml:"match" json:"match"`
+
+		// Dir is the directory above the Path that the Rule pruned, or empty when the Rule decided on the Path itself
+		Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+
+		// Type is the Classify type of a file that reached the RuleExcludeExt and RuleIncludeExt
+		Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	}
+
+	// Listing is a path that Summarizer.List reached, with the Explanation of the rule that includes or excludes it
+	Listing struct {
+		Explanation `yaml:",inline"`
+
+		// IsDir is set on a directory that was pruned along with everything inside of it
+		IsDir bool `yaml:"is_dir,omitempty" json:"is_dir,omitempty"`
+
+		// Size is the size in bytes of a file
+		Size int64 `yaml:"size" json:"size"`
+
+		// Tokens are the estimated tokens of the contents of an included file, after it is truncated
+		Tokens int `yaml:"tokens" json:"tokens"`
+	}
+
+	// Summary is the rendered result of a Summarizer Run. When Options.Chunk splits it into Chunks, the Contents are
+	// empty and the Size is the sum of the Size of every Chunk.
+	Summary struct {
+		Workspace  string       `yaml:"workspace" json:"workspace"`
+		Roots      []Root       `yaml:"roots,omitempty" json:"roots,omitempty"`
+		Results    []Result     `yaml:"results" json:"results"`
+		Contents   []byte       `yaml:"contents" json:"contents"`
+		Size       int64        `yaml:"size" json:"size"`
+		Tokens     int          `yaml:"tokens" json:"tokens"`
+		Omitted    []Omission   `yaml:"omitted" json:"omitted"`
+		Redactions []Redaction  `yaml:"redactions" json:"redactions"`
+		Truncated  []Truncation `yaml:"truncated" json:"truncated"`
+		Errors     []FileError  `yaml:"errors" json:"errors"`
+		Chunks     []Chunk      `yaml:"chunks" json:"chunks"`
+	}
+
+	// Chunk is the Part of a Summary split by Options.Chunk that holds the Files in its Contents
+	Chunk struct {
+		Part     int    `yaml:"part" json:"part"`
+		Files    []File `yaml:"files" json:"files"`
+		Contents []byte `yaml:"contents" json:"contents"`
+		Size     int64  `yaml:"size" json:"size"`
+		Tokens   int    `yaml:"tokens" json:"tokens"`
+	}
+
+	// Piece is the Index of Count consecutive parts of a file, from its FirstLine to its LastLine, that was split
+	// across chunks by Options.Chunk because it was over the limits by itself
+	Piece struct {
+		Index     int `yaml:"index" json:"index" xml:"index,attr"`
+		Count     int `yaml:"count" json:"count" xml:"count,attr"`
+		FirstLine int `yaml:"first_line" json:"first_line" xml:"first_line,attr"`
+		LastLine  int `yaml:"last_line" json:"last_line" xml:"last_line,attr"`
+	}
+
+	// Document is a summary in the Format that was read back into the Results it was rendered from by Parse
+	Document struct {
+		Format    string   `yaml:"format" json:"format"`
+		Workspace string   `yaml:"workspace" json:"workspace"`
+		Results   []Result `yaml:"results" json:"results"`
+	}
+
+	// Change is a file at the Path relative to its workspace that was ChangeAdded, ChangeRemoved or ChangeModified
+	// between two Documents, with the lines Added and Removed by its unified Diff
+	Change struct {
+		Path    string `yaml:"path" json:"path"`
+		Status  string `yaml:"status" json:"status"`
+		Added   int    `yaml:"added" json:"added"`
+		Removed int    `yaml:"removed" json:"removed"`
+		Diff    string `yaml:"diff" json:"diff"`
+	}
+
+	// Patch is a change that a model response proposes to the file at the Path relative to the workspace, from the
+	// contents it had Before, when it Exists, to the contents it has After, or to its deletion. Diff is the unified
+	// diff from Before to After.
+	Patch struct {
+		Path   string `yaml:"path" json:"path"`
+		Exists bool   `yaml:"exists" json:"exists"`
+		Delete bool   `yaml:"delete" json:"delete"`
+		Before string `yaml:"before" json:"before"`
+		After  string `yaml:"after" json:"after"`
+		Diff   string `yaml:"diff" json:"diff"`
+	}
+
+	// Error is a failure of New or Run with the Kind of step that failed, such as KindRead, and the Path of the file it
+	// failed on when there is one
+	Error struct {
+		Kind string
+		Path string
+		Err  error
+	}
+
+	// patcher collects the Patches of a response to the files of the workspace that root opens, where index finds the
+	// Patch of a path
+	patcher struct {
+		workspace string
+		root      *os.Root
+		patches   []Patch
+		index     map[string]int
+	}
+
+	// hunk replaces the old lines of a file that start around the line start with the new lines
+	hunk struct {
+		start    int
+		old, new []string
+	}
+
+	// edit is a line of a diff that is kept (' '), removed ('-') or added ('+')
+	edit struct {
+		op   byte
+		line string
+	}
+
+	// Omission is a path that matched the conditions of the Summary but was left out of it for the Reason
+	Omission struct {
+		Path   string `yaml:"path" json:"path" xml:"path,attr"`
+		Size   int64  `yaml:"size" json:"size" xml:"size,attr"`
+		Reason string `yaml:"reason" json:"reason" xml:"reason,attr"`
+	}
+
+	// FileError is a file that was skipped because the step of the Kind failed on it with the Cause, or a header or
+	// footer that failed with an empty Path
+	FileError struct {
+		Path  string `yaml:"path" json:"path" xml:"path,attr"`
+		Kind  string `yaml:"kind" json:"kind" xml:"kind,attr"`
+		Cause string `yaml:"cause" json:"cause" xml:"cause,attr"`
+	}
+
+	// Redaction is a secret of the Kind that was found in the Path on the Line and replaced in the Summary
+	Redaction struct {
+		Path string `yaml:"path" json:"path" xml:"path,attr"`
+		Line int    `yaml:"line" json:"line" xml:"line,attr"`
+		Kind string `yaml:"kind" json:"kind" xml:"kind,attr"`
+	}
+
+	// Truncation describes a file of Lines and Size that was rendered with only its first Head and last Tail lines
+	Truncation struct {
+		Path   string `yaml:"path" json:"path" xml:"path,attr"`
+		Lines  int    `yaml:"lines" json:"lines" xml:"lines,attr"`
+		Size   int64  `yaml:"size" json:"size" xml:"size,attr"`
+		Head   int    `yaml:"head" json:"head" xml:"head,attr"`
+		Tail   int    `yaml:"tail" json:"tail" xml:"tail,attr"`
+		Elided int    `yaml:"elided" json:"elided" xml:"elided,attr"`
+	}
+
+	// cache is the file of Options.CacheFile, whose Entries are keyed by the absolute path of their file and only reused
+	// by runs with the same Fingerprint of the Options
+	cache struct {
+		Fingerprint string
+		Entries     map[string]cacheEntry
+	}
+
+	// cacheEntry is a file of Size, ModTime, Mode and Hash that was either left out of the summary for the Omit reason
+	// or prepared into the Contents, Redactions, Truncation and Tokens of its Result, with the Cost of the Result as it
+	// was rendered for the Language
+	cacheEntry struct {
+		Size       int64
+		ModTime    time.Time
+		Mode       os.FileMode
+		Hash       string
+		Omit       string
+		Contents   string
+		Redactions []Redaction
+		Truncation *Truncation
+		Tokens     int
+		Language   string
+		Cost       int
+	}
+
+	// redactor finds one kind of secret with re, replacing the submatch group when the optional check accepts it
+	redactor struct {
+		kind    string
+		re      *regexp.Regexp
+		group   int
+		envOnly bool
+		check   func([]byte) bool
+	}
+
+	// File describes a path in the SourceDir that was rendered into the Summary, where the Path is slash separated and
+	// relative to the SourceDir, or to its Root under the Alias of the Root. Size, Mode, ModTime and Hash (the SHA-256
+	// in hex) describe the file on disk, while Tokens estimates the Contents that were rendered.
+	File struct {
+		Path       string      `yaml:"path" json:"path" xml:"path,attr"`
+		Language   string      `yaml:"language" json:"language" xml:"language,attr"`
+		Size       int64       `yaml:"size" json:"size" xml:"size,attr"`
+		Mode       os.FileMode `yaml:"mode" json:"mode" xml:"mode,attr"`
+		ModTime    time.Time   `yaml:"mtime" json:"mtime" xml:"mtime,attr"`
+		Hash       string      `yaml:"hash" json:"hash" xml:"hash,attr"`
+		Tokens     int         `yaml:"tokens" json:"tokens" xml:"tokens,attr"`
+		Truncation *Truncation `yaml:"truncation,omitempty" json:"truncation,omitempty" xml:"truncation,omitempty"`
+		Piece      *Piece      `yaml:"piece,omitempty" json:"piece,omitempty" xml:"piece,omitempty"`
+	}
+
+	// Result is a File that matched the conditions along with the Contents that are rendered into the Summary, after
+	// they were redacted and truncated. Every renderer of an Options.Format renders the same Result.
+	Result struct {
+		File     `yaml:",inline"`
+		Contents string `yaml:"contents" json:"contents"`
+		rendered []byte
+		cost     int
+	}
+
+	// header is rendered at the start of a summary in every Options.Format, where Files are only available to templates
+	// and the Part of the Parts is only set by Options.Chunk
+	header struct {
+		Project      string `yaml:"project" json:"project"`
+		Version      string `yaml:"version" json:"version"`
+		Filename     string `yaml:"filename" json:"filename"`
+		Workspace    string `yaml:"workspace,omitempty" json:"workspace,omitempty"`
+		Roots        []Root `yaml:"roots,omitempty" json:"roots,omitempty"`
+		Instructions string `yaml:"instructions" json:"instructions"`
+		Part         int    `yaml:"part,omitempty" json:"part,omitempty"`
+		Parts        int    `yaml:"parts,omitempty" json:"parts,omitempty"`
+		Files        []File `yaml:"-" json:"-"`
+	}
+
+	// footer is rendered at the end of a summary in every Options.Format
+	footer struct {
+		XMLName    xml.Name     `yaml:"-" json:"-" xml:"report"`
+		Truncated  []Truncation `yaml:"truncated" json:"truncated" xml:"truncated"`
+		Redactions []Redaction  `yaml:"redactions" json:"redactions" xml:"redaction"`
+		Omitted    []Omission   `yaml:"omitted" json:"omitted" xml:"omitted"`
+		Errors     []FileError  `yaml:"errors" json:"errors" xml:"error"`
+	}
+
+	// renderer renders the header, each Result and the footer of a summary in one Options.Format, with the separator
+	// between each of the results
+	renderer interface {
+		header(h header) ([]byte, error)
+		file(in Result) ([]byte, error)
+		separator() []byte
+		footer(f footer) ([]byte, error)
+	}
+
+	// templateRenderer renders FormatMarkdown with the "header", "file" and "footer" templates of tmpl
+	templateRenderer struct {
+		tmpl *template.Template
+	}
+
+	// jsonRenderer renders FormatJSON
+	jsonRenderer struct{}
+
+	// yamlRenderer renders FormatYAML
+	yamlRenderer struct{}
+
+	// jsonlRenderer renders FormatJSONL
+	jsonlRenderer struct{}
+
+	// xmlRenderer renders FormatXML
+	xmlRenderer struct{}
+
+	// run holds the state of a single Summarizer Run
+	run struct {
+		ctx                         context.Context
+		cancel                      context.CancelCauseFunc
+		opts                        Options
+		renderer                    renderer
+		ignore                      *ignorer
+		priority                    []*regexp.Regexp
+		entryPoints                 []*regexp.Regexp
+		demote                      []*regexp.Regexp
+		data                        *sync.Map
+		seen                        *seenStrings
+		results                     chan Result
+		wg, writerWG                *sync.WaitGroup
+		throttler, maxFileSemaphore sema.Semaphore
+		toUpdateMu                  sync.Mutex
+		toUpdate                    []mapData
+		errs                        *collector
+		buf                         bytes.Buffer
+		tokens                      int
+		rendered                    []Result
+		omitMu                      sync.Mutex
+		omitted                     []Omission
+		redactMu                    sync.Mutex
+		redactions                  []Redaction
+		truncated                   []Truncation
+		chunks                      []Chunk
+		cacheMu                     sync.Mutex
+		cache                       *cache
+		cacheHits                   int
+		list                        bool
+		listed                      []Listing
+		root                        Root
+		names                       map[string]string
+		filters
+	}
+
+	// ignorer matches paths relative to root against the gitignore syntax ignore files loaded per directory
+	ignorer struct {
+		root  string
+		files map[string][]*ignoreFile
+	}
+
+	// ignoreFile is a parsed ignore file whose rules are relative to dir
+	ignoreFile struct {
+		path  string
+		dir   string
+		rules []ignoreRule
+	}
+
+	// ignoreRule is a single pattern of an ignoreFile compiled into a regular expression
+	ignoreRule struct {
+		pattern string
+		line    int
+		negate  bool
+		dirOnly bool
+		re      *regexp.Regexp
+	}
+
+	// gitHash is a sha1 object name of a git repository
+	gitHash [20]byte
+
+	// gitRepo reads the index, refs and objects of a local git repository without the git binary
+	gitRepo struct {
+		worktree    string
+		gitDir      string
+		commonDir   string
+		packs       []*gitPack
+		packsLoaded bool
+	}
+
+	// gitPack is the parsed .idx of a .pack file in the objects/pack directory
+	gitPack struct {
+		path    string
+		fanout  [256]uint32
+		names   []gitHash
+		offsets []int64
+	}
+
+	// gitIndexEntry is a file staged in the index of a git repository
+	gitIndexEntry struct {
+		path      string
+		hash      gitHash
+		mode      uint32
+		size      uint32
+		mtimeSec  uint32
+		mtimeNsec uint32
+		stage     int
+	}
+
+	// gitCommit is the part of a commit object needed to list files and find merge bases
+	gitCommit struct {
+		hash    gitHash
+		tree    gitHash
+		parents []gitHash
+		time    int64
+	}
+
+	// gitCommitQueue is a heap of commits ordered by the newest committer time
+	gitCommitQueue []*gitCommit
+
+	// collector gathers the errors of the goroutines of a run and is safe for concurrent use
+	collector struct {
+		mu   sync.Mutex
+		errs []*Error
+	}
+
+	// seenStrings captures a concurrent safe map of strings and booleans that indicate whether the string has been seen
+	seenStrings struct {
+		mu sync.RWMutex
+		m  map[string]bool
+	}
+
+	mapData struct {
+		Ext   string
+		Paths []string
+	}
+)
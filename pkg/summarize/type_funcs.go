@@ -1,6 +1,43 @@
-package main
+package summarize
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Add records the err
+func (c *collector) Add(err *Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs = append(c.errs, err)
+}
+
+// Err returns the errors that were added joined together, or nil when there are none
+func (c *collector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs := make([]error, 0, len(c.errs))
+	for _, err := range c.errs {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// FileErrors returns a FileError for every error that was added, sorted by path
+func (c *collector) FileErrors() []FileError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]FileError, 0, len(c.errs))
+	for _, err := range c.errs {
+		out = append(out, FileError{Path: err.Path, Kind: err.Kind, Cause: err.Err.Error()})
+	}
+	slices.SortStableFunc(out, func(a, b FileError) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return out
+}
 
 // Add inserts an entry into the map
 func (s *seenStrings) Add(entry string) {
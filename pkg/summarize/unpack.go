@@ -0,0 +1,126 @@
+package summarize
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// elision matches the marker that truncate leaves in place of the lines it elided
+	elision = regexp.MustCompile(`(?m)^\[\.\.\. \d+ of \d+ lines \(\d+ bytes\) elided by summarize \.\.\.\]$`)
+
+	// placeholder matches the placeholder that redact leaves in place of a secret
+	placeholder = regexp.MustCompile(`\[REDACTED:[a-z0-9-]+\]`)
+)
+
+// Unpack recreates every file of the Document inside dir at its path relative to the Workspace with the mode it was
+// summarized with, and returns the relative paths that were written, the ones that were skipped because they already
+// exist, and the ones whose contents were truncated or redacted. Existing files are only overwritten, and truncated or
+// redacted files are only written as they were summarized, when force is set. Nothing is written when the path of any
+// file would escape dir, in which case the error wraps ErrPathTraversal.
+func (d *Document) Unpack(dir string, force bool) (written, skipped, altered []string, err error) {
+	paths := make([]string, len(d.Results))
+	for i, in := range d.Results {
+		if paths[i], err = d.unpackPath(in.Path); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, nil, fmt.Errorf("creating %s: %w", dir, err)
+	}
+	// the root refuses to follow a symlink inside of dir that points outside of it
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer root.Close()
+	for i, in := range d.Results {
+		if in.altered() {
+			altered = append(altered, paths[i])
+			if !force {
+				continue // the contents are not the source of the file
+			}
+		}
+		ok, err := unpackFile(root, paths[i], in, force)
+		if err != nil {
+			return written, skipped, altered, fmt.Errorf("unpacking %s: %w", paths[i], err)
+		}
+		if ok {
+			written = append(written, paths[i])
+		} else {
+			skipped = append(skipped, paths[i])
+		}
+	}
+	return written, skipped, altered, nil
+}
+
+// altered reports whether the Contents of the Result were truncated or redacted, which Parse can only tell from the
+// elision marker or the placeholder of a secret when the format does not record the Truncation
+func (in Result) altered() bool {
+	return in.Truncation != nil || elision.MatchString(in.Contents) || placeholder.MatchString(in.Contents)
+}
+
+// unpackPath returns the slash separated path of the file at p relative to the Workspace, refusing the paths that are
+// outside of the Workspace or that climb out of it
+func (d *Document) unpackPath(p string) (string, error) {
+	rel := d.relativePath(p)
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", fmt.Errorf("%w: %s", ErrPathTraversal, p)
+	}
+	return rel, nil
+}
+
+// relativePath returns the cleaned slash separated path of the file at p relative to the Workspace. The files are
+// summarized by their path relative to the Workspace already, but an absolute p inside the Workspace is made relative
+// to it as well.
+func (d *Document) relativePath(p string) string {
+	if len(d.Workspace) > 0 && filepath.IsAbs(p) {
+		rel, err := filepath.Rel(filepath.Clean(d.Workspace), filepath.Clean(p))
+		if err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path.Clean(filepath.ToSlash(p))
+}
+
+// unpackFile writes the contents of the Result to the rel path of the root along with its mode, creating its parent
+// directories, and reports false when the file already exists and force is not set
+func unpackFile(root *os.Root, rel string, in Result, force bool) (bool, error) {
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if err := root.Mkdir(strings.Join(parts[:i], "/"), 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return false, err
+		}
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	perm := in.Mode.Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+	f, err := root.OpenFile(rel, flags, perm)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.WriteString(in.Contents); err != nil {
+		_ = f.Close()
+		return false, err
+	}
+	// the umask applies to the mode of a new file and an existing file keeps its own until it is changed
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
@@ -0,0 +1,136 @@
+package summarize
+
+import "errors"
+
+var (
+	// ErrSecretFound is returned by Run when Options.FailOnSecret is enabled and a secret was found
+	ErrSecretFound = errors.New("secret found")
+
+	// ErrPathTraversal is returned by Document.Unpack when the path of a file would escape the directory it unpacks to
+	ErrPathTraversal = errors.New("path escapes the unpack directory")
+
+	// DefaultExcludeExt are the extensions that will be skipped automatically
+	DefaultExcludeExt = []string{
+		// Compressed archives
+		"7z", "gz", "xz", "zst", "zstd", "bz", "bz2", "bzip2", "zip", "tar", "rar", "lz4", "lzma", "cab", "arj",
+
+		// Encryption, certificates, and sensitive keys
+		"crt", "cert", "cer", "key", "pub", "asc", "pem", "p12", "pfx", "jks", "keystore",
+		"id_rsa", "id_dsa", "id_ed25519", "id_ecdsa", "gpg", "pgp",
+
+		// Binary & executable artifacts
+		"exe", "dll", "so", "dylib", "bin", "out", "o", "obj", "a", "lib", "dSYM",
+		"class", "pyc", "pyo", "__pycache__",
+		"jar", "war", "ear", "apk", "ipa", "dex", "odex",
+		"wasm", "node", "beam", "elc",
+
+		// System and disk images
+		"iso", "img", "dmg", "vhd", "vdi", "vmdk", "qcow2",
+
+		// Database files
+		"db", "sqlite", "sqlite3", "db3", "mdb", "accdb", "sdf", "ldb",
+
+		// Log files
+		"log", "trace", "dump", "crash",
+
+		// Media files - Images
+		"jpg", "jpeg", "png", "gif", "bmp", "tiff", "tif", "webp", "ico", "svg", "heic", "heif", "raw", "cr2", "nef", "dng",
+
+		// Media files - Audio
+		"mp3", "wav", "flac", "aac", "ogg", "wma", "m4a", "opus", "aiff",
+
+		// Media files - Video
+		"mp4", "avi", "mov", "mkv", "webm", "flv", "wmv", "m4v", "3gp", "ogv",
+
+		// Font files
+		"ttf", "otf", "woff", "woff2", "eot", "fon", "pfb", "pfm",
+
+		// Document formats (typically not source code)
+		"pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "odt", "ods", "odp", "rtf",
+
+		// IDE/Editor/Tooling artifacts
+		"suo", "sln", "user", "ncb", "pdb", "ipch", "ilk", "tlog", "idb", "aps", "res",
+		"iml", "idea", "vscode", "project", "classpath", "factorypath", "prefs",
+		"vcxproj", "vcproj", "filters", "xcworkspace", "xcuserstate", "xcscheme", "pbxproj",
+		"DS_Store", "Thumbs.db", "desktop.ini",
+
+		// Package manager and build artifacts
+		"lock", "sum", "resolved", // package-lock.json, go.sum, yarn.lock, etc.
+
+		// Temporary and backup files
+		"tmp", "temp", "swp", "swo", "bak", "backup", "orig", "rej", "patch",
+		"~", "old", "new", "part", "incomplete",
+
+		// Source maps and minified files (usually generated)
+		"map", "min.js", "min.css", "bundle.js", "bundle.css", "chunk.js",
+
+		// Configuration that's typically binary or generated
+		"dat", "data", "cache", "pid", "sock",
+
+		// Version control artifacts (though usually in ignored directories)
+		"pack", "idx", "rev",
+
+		// Other binary formats
+		"pickle", "pkl", "npy", "npz", "mat", "rdata", "rds",
+	}
+
+	// DefaultIncludeExt are the extensions that will be included in the summary
+	DefaultIncludeExt = []string{
+		"go", "ts", "tf", "sh", "py", "js", "Makefile", "mod", "Dockerfile", "dockerignore", "gitignore", "esconfigs", "md",
+	}
+
+	// DefaultSkipContains are the substrings in file path names to avoid in the summary
+	DefaultSkipContains = []string{
+		".min.js", ".min.css", ".git/", ".svn/", ".vscode/", ".vs/", ".idea/", "logs/", "secrets/",
+		".venv/", "/site-packages", ".terraform/", "summaries/", "node_modules/", "/tmp", "tmp/", "logs/",
+	}
+
+	// DefaultEntryPoints are the globs of files that are boosted when Options.Pack ranks the files
+	DefaultEntryPoints = []string{
+		"README*", "main.go", "go.mod", "cmd/*/main.go", "Makefile", "Dockerfile",
+		"package.json", "index.js", "index.ts", "main.ts", "main.js",
+		"pyproject.toml", "setup.py", "main.py", "__main__.py", "app.py",
+		"Cargo.toml", "main.rs", "lib.rs", "main.tf",
+	}
+
+	// DefaultDemote are the globs of tests, fixtures and vendored code that are demoted when Options.Pack ranks the files
+	DefaultDemote = []string{
+		// Tests
+		"*_test.go", "test_*.py", "*_test.py", "*.test.js", "*.test.ts", "*.spec.js", "*.spec.ts",
+		"**/test/**", "**/tests/**", "**/__tests__/**",
+
+		// Fixtures
+		"**/testdata/**", "**/fixtures/**", "**/__fixtures__/**", "**/__snapshots__/**", "*.snap", "*.golden",
+
+		// Vendored code
+		"**/vendor/**", "**/third_party/**", "**/node_modules/**",
+	}
+)
+
+// specialNames are the Classify types of the file names that have no extension of their own
+var specialNames = map[string]string{
+	"Makefile": "Makefile", "makefile": "Makefile", "GNUmakefile": "Makefile",
+	"Dockerfile": "Dockerfile", "dockerfile": "Dockerfile", "Containerfile": "Dockerfile",
+	"Jenkinsfile": "groovy", "Vagrantfile": "rb", "Gemfile": "rb", "Rakefile": "rb",
+}
+
+// interpreters are the Classify types of the interpreters that a shebang runs, without their version
+var interpreters = map[string]string{
+	"sh": "sh", "bash": "sh", "dash": "sh", "ksh": "sh", "ash": "sh", "zsh": "zsh",
+	"python": "py", "pypy": "py", "node": "js", "nodejs": "js", "deno": "ts", "ts-node": "ts", "bun": "js",
+	"ruby": "rb", "perl": "pl", "php": "php", "lua": "lua", "Rscript": "r", "pwsh": "ps1", "tclsh": "tcl",
+	"awk": "awk", "gawk": "awk",
+}
+
+// languages are the names of the programming languages that the "language" template helper returns for an extension
+var languages = map[string]string{
+	"bash": "Bash", "c": "C", "cc": "C++", "clj": "Clojure", "cpp": "C++", "cs": "C#", "css": "CSS", "cxx": "C++",
+	"dart": "Dart", "ex": "Elixir", "exs": "Elixir", "erl": "Erlang", "fs": "F#", "go": "Go", "gradle": "Gradle",
+	"graphql": "GraphQL", "groovy": "Groovy", "h": "C", "hpp": "C++", "hs": "Haskell", "html": "HTML", "java": "Java",
+	"js": "JavaScript", "json": "JSON", "jsx": "JavaScript", "kt": "Kotlin", "kts": "Kotlin", "lua": "Lua",
+	"m": "Objective-C", "md": "Markdown", "mjs": "JavaScript", "ml": "OCaml", "mod": "Go Module", "php": "PHP",
+	"pl": "Perl", "proto": "Protocol Buffers", "ps1": "PowerShell", "py": "Python", "r": "R", "rb": "Ruby",
+	"rs": "Rust", "sass": "Sass", "scala": "Scala", "scss": "SCSS", "sh": "Shell", "sql": "SQL", "svelte": "Svelte",
+	"swift": "Swift", "tf": "Terraform", "toml": "TOML", "ts": "TypeScript", "tsx": "TypeScript", "vue": "Vue",
+	"xml": "XML", "yaml": "YAML", "yml": "YAML", "zig": "Zig", "zsh": "Zsh",
+}
@@ -0,0 +1,61 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchmarkTree writes a project of sources files under dir, with a node_modules of packages that hold files each
+func benchmarkTree(b *testing.B, dir string, sources, packages, files int) {
+	b.Helper()
+	tree := make(map[string]string)
+	for i := 0; i < sources; i++ {
+		tree[fmt.Sprintf("pkg%d/file%d.go", i%10, i)] = fmt.Sprintf("package pkg%d\n", i%10)
+	}
+	for p := 0; p < packages; p++ {
+		for f := 0; f < files; f++ {
+			tree[fmt.Sprintf("node_modules/pkg%d/lib/file%d.js", p, f)] = "module.exports = {}\n"
+		}
+	}
+	writeTree(b, dir, tree)
+}
+
+// BenchmarkRunNodeModules summarizes the same sources with and without a large node_modules next to them. With the
+// node_modules skipped, the directory is pruned and costs the same at any size, while without the skip the walk
+// reaches each of its files and filters them one at a time by their type.
+func BenchmarkRunNodeModules(b *testing.B) {
+	for _, packages := range []int{0, 100, 400} {
+		dir := b.TempDir()
+		benchmarkTree(b, dir, 50, packages, 25)
+		for _, walk := range []struct {
+			name string
+			skip []string
+		}{
+			{"pruned", []string{"node_modules/"}},
+			{"unpruned", nil},
+		} {
+			b.Run(fmt.Sprintf("packages=%d/%s", packages, walk.name), func(b *testing.B) {
+				opts := treeOptions(dir, walk.skip...)
+				opts.IncludeExt = []string{"go"}
+				s, err := New(opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					summary, err := s.Run(context.Background())
+					if err != nil {
+						b.Fatal(err)
+					}
+					for _, in := range summary.Results {
+						if strings.Contains(in.Path, "node_modules") {
+							b.Fatalf("%s in node_modules was summarized", in.Path)
+						}
+					}
+				}
+			})
+		}
+	}
+}
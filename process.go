@@ -1,44 +1,82 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
-	"sync"
 
 	check "github.com/andreimerlescu/checkfs"
 	"github.com/andreimerlescu/checkfs/directory"
-	"github.com/andreimerlescu/sema"
+	"github.com/andreimerlescu/summarize/pkg/summarize"
 )
 
-func process() {
-	preprocess()
-	defer postprocess()
-	// populate data with the kSourceDir files based on -inc -exc -avoid lists
-	capture("walking source directory", filepath.Walk(sourceDir, summarize))
+func process() error {
+	if err := preprocess(); err != nil {
+		return err
+	}
+	opts, err := options()
+	if err != nil {
+		return err
+	}
+	summarizer, err := summarize.New(opts)
+	if err != nil {
+		return fail(summarize.KindConfig, "configuring summarizer", err)
+	}
+	if path := *figs.String(kExplain); len(path) > 0 {
+		return explain(summarizer, path)
+	}
+	// populate the summary with the kSourceDir files based on -inc -exc -avoid lists
+	summary, err := summarizer.Run(context.Background())
+	return postprocess(summary, err)
+}
 
-	if isDebug {
-		fmt.Println("data received: ")
-		data.Range(func(e any, p any) bool {
-			ext, ok := e.(string)
-			if !ok {
-				return true // continue
-			}
-			thisData, ok := p.(mapData)
-			if !ok {
-				return true // continue
+// load configures the figs and loads them from the environment and the command line arguments, where the flags can
+// follow the arguments of a command such as `summarize unpack <summary> -to <dir>`
+func load() error {
+	configure()
+	os.Args = append(os.Args[:1:1], flagsFirst(os.Args[1:])...)
+	return fail(summarize.KindConfig, "figs loading environment", figs.Load())
+}
+
+// flagsFirst moves the flags in args and their values in front of the other arguments, which follow a "--" so that
+// flag.Args returns them once the flags are parsed
+func flagsFirst(args []string) []string {
+	var flags, rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			rest = append(rest, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := flag.CommandLine.Lookup(name); f != nil && i+1 < len(args) {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				i++
+				flags = append(flags, args[i])
 			}
-			fmt.Printf("%s: %s\n", ext, strings.Join(thisData.Paths, ", "))
-			return true // continue
-		})
+		}
 	}
+	if len(rest) == 0 {
+		return flags
+	}
+	return append(append(flags, "--"), rest...)
 }
 
-func preprocess() {
-	configure()
-	capture("figs loading environment", figs.Load())
+func preprocess() error {
+	if err := load(); err != nil {
+		return err
+	}
 
 	isDebug = *figs.Bool(kDebug)
 
@@ -68,70 +106,126 @@ func preprocess() {
 		fmt.Printf("-%s=%s\n", kIncludeExt, strings.Join(*figs.List(kIncludeExt), ","))
 		fmt.Printf("-%s=%s\n", kExcludeExt, strings.Join(*figs.List(kExcludeExt), ","))
 		fmt.Printf("-%s=%s\n", kSkipContains, strings.Join(*figs.List(kSkipContains), ","))
-		os.Exit(0)
+		return errDone
 	}
 
 	if *figs.Bool(kVersion) {
 		fmt.Println(Version())
-		os.Exit(0)
+		return errDone
 	}
 
 	lIncludeExt = *figs.List(kIncludeExt)
 	lExcludeExt = *figs.List(kExcludeExt)
 	lSkipContains = *figs.List(kSkipContains)
 
-	sourceDir = *figs.String(kSourceDir)
+	if format := *figs.String(kFormat); format != summarize.FormatMarkdown {
+		if name := *figs.String(kFilename); strings.HasSuffix(name, ".md") {
+			figs.StoreString(kFilename, strings.TrimSuffix(name, ".md")+"."+format)
+		}
+	}
+
+	if sourceRoots = parseRoots(*figs.String(kSourceDir)); len(sourceRoots) == 0 {
+		return usage("[-d <dir>|<alias>=<dir>,...] [flags]")
+	}
+	sourceDir = sourceRoots[0].Dir
 	outputDir = *figs.String(kOutputDir)
-	capture("checking output directory", check.Directory(outputDir, directory.Options{
+	err := check.Directory(outputDir, directory.Options{
 		WillCreate: true,
 		Create: directory.Create{
 			Kind:     directory.IfNotExists,
 			Path:     outputDir,
 			FileMode: 0755,
 		},
-	}))
+	})
+	if err != nil {
+		return fail(summarize.KindConfig, "checking output directory", err)
+	}
 
 	addFromEnv(eAddIgnoreInPathList, &lSkipContains)
 	addFromEnv(eAddIncludeExtList, &lIncludeExt)
 	addFromEnv(eAddExcludeExtList, &lExcludeExt)
+	return nil
+}
 
-	wg = &sync.WaitGroup{}
-	throttler = sema.New(runtime.GOMAXPROCS(0))
-	data = &sync.Map{}
-
-	for _, i := range lIncludeExt {
-		data.Store(i, mapData{
-			Ext:   i,
-			Paths: []string{},
-		})
+// options returns the summarize.Options built from the figs that were loaded in preprocess
+func options() (summarize.Options, error) {
+	tokenizer, err := summarize.LookupTokenizer(*figs.String(kTokenizer))
+	if err != nil {
+		return summarize.Options{}, fail(summarize.KindConfig, "configuring summarizer", err)
 	}
-
+	var tmpl []byte
+	if path := *figs.String(kTemplate); len(path) > 0 {
+		if tmpl, err = os.ReadFile(path); err != nil {
+			return summarize.Options{}, fail(summarize.KindRead, "reading template", err)
+		}
+	}
+	var cacheFile string
+	if !*figs.Bool(kNoCache) {
+		cacheFile = filepath.Join(outputDir, summarize.CacheFilename)
+	}
+	return summarize.Options{
+		SourceDir:       sourceDir,
+		Roots:           sourceRoots,
+		Filename:        *figs.String(kFilename),
+		Version:         Version(),
+		IncludeExt:      lIncludeExt,
+		ExcludeExt:      lExcludeExt,
+		SkipContains:    lSkipContains,
+		Dots:            dotsPolicy(*figs.String(kDots), *figs.Bool(kDotFiles)),
+		DotAllow:        *figs.List(kDotAllow),
+		IncludeGlob:     *figs.List(kIncludeGlob),
+		ExcludeGlob:     splitList(*figs.String(kExcludeGlob)),
+		ExcludeRegex:    *figs.List(kExcludeRegex),
+		SkipBinary:      *figs.Bool(kSkipBinary),
+		SkipInvalidUTF8: *figs.Bool(kSkipInvalidUTF8),
+		MaxLineLength:   *figs.Int(kMaxLineLength),
+		SkipGenerated:   *figs.Bool(kSkipGenerated),
+		Redact:          *figs.Bool(kRedact),
+		FailOnSecret:    *figs.Bool(kFailOnSecret),
+		GitIgnore:       *figs.Bool(kGitIgnore),
+		DockerIgnore:    *figs.Bool(kDockerIgnore),
+		SummarizeIgnore: *figs.Bool(kSummarizeIgnore),
+		Git:             *figs.String(kGit),
+		GitSince:        *figs.String(kGitSince),
+		GitMergeBase:    *figs.Bool(kGitMergeBase),
+		MaxFileBytes:    *figs.Int64(kMaxFileBytes),
+		MaxFileLines:    *figs.Int(kMaxFileLines),
+		HeadLines:       *figs.Int(kHeadLines),
+		TailLines:       *figs.Int(kTailLines),
+		MaxOutputSize:   *figs.Int64(kMaxOutputSize),
+		Template:        string(tmpl),
+		Format:          *figs.String(kFormat),
+		Order:           *figs.String(kOrder),
+		Priority:        splitList(*figs.String(kPriority)),
+		Pack:            *figs.Bool(kPack),
+		Chunk:           *figs.Bool(kChunk),
+		MaxTokens:       *figs.Int(kMaxTokensOut),
+		Tokenizer:       tokenizer,
+		CacheFile:       cacheFile,
+		MaxFiles:        *figs.Int(kMaxFiles),
+		Debug:           isDebug,
+	}, nil
 }
 
-func postprocess() {
-	maxFileSemaphore = sema.New(*figs.Int(kMaxFiles))
-	resultsChan = make(chan Result, *figs.Int(kMaxFiles))
-	writerWG = &sync.WaitGroup{}
-	writerWG.Add(1)
-	go receive()
-
-	seen = &seenStrings{m: make(map[string]bool)}
-
-	data.Range(iterate)
-
-	wg.Wait() // wait for all files to finish processing
-
-	for _, innerData := range toUpdate {
-		data.Store(innerData.Ext, innerData)
+// postprocess writes the summary that Run returned, which lists the files that failed under its Errors. With -strict
+// the err of Run is returned once the summary of the other files was written, otherwise the failed files are only
+// counted on STDERR.
+func postprocess(summary *summarize.Summary, err error) error {
+	if summary == nil {
+		return fail(kindOf(err), "summarizing source directory", err)
 	}
 
-	close(resultsChan) // Signal the writer goroutine to finish
-	writerWG.Wait()    // Wait for the writer to flush and close the file
-
-	if len(errs) > 0 {
-		terminate(os.Stderr, "Error writing to output file: %v\n", errs)
+	generated, writeErr := receive(summary)
+	if writeErr != nil {
+		return writeErr
 	}
 
-	done()
-
+	if len(generated) > 0 {
+		done(generated)
+	}
+	if err != nil && !*figs.Bool(kStrict) {
+		_, _ = fmt.Fprintf(os.Stderr, "Skipped %d files with errors, they are listed under Errors in the summary\n", len(summary.Errors))
+		return nil
+	}
+	return fail(kindOf(err), "summarizing source directory", err)
 }
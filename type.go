@@ -1,38 +1,69 @@
 package main
 
-import (
-	"sync"
-)
+import "github.com/andreimerlescu/summarize/pkg/summarize"
 
 type (
 
-	// Result contains the scanned path in the kSourceDir that matched the conditions and shall be included in the Final summary of kFilename
-	Result struct {
-		Path     string `yaml:"path" json:"path"`
-		Contents []byte `yaml:"contents" json:"contents"`
-		Size     int64  `yaml:"size" json:"size"`
+	// Final contains the rendered Summary of the matched paths that gets written to kFilename
+	Final struct {
+		Path       string                 `yaml:"path" json:"path"`
+		Contents   string                 `yaml:"contents" json:"contents"`
+		Size       int64                  `yaml:"size" json:"size"`
+		Tokens     int                    `yaml:"tokens" json:"tokens"`
+		Files      []summarize.File       `yaml:"files" json:"files"`
+		Omitted    []summarize.Omission   `yaml:"omitted" json:"omitted"`
+		Redactions []summarize.Redaction  `yaml:"redactions" json:"redactions"`
+		Truncated  []summarize.Truncation `yaml:"truncated" json:"truncated"`
+		Errors     []summarize.FileError  `yaml:"errors" json:"errors"`
 	}
 
-	// Final contains the rendered Result of the matched path that gets written to kFilename
-	Final struct {
-		Path     string `yaml:"path" json:"path"`
-		Contents string `yaml:"contents" json:"contents"`
-		Size     int64  `yaml:"size" json:"size"`
+	// Manifest lists the Chunks that a summary split by -chunk was written to
+	Manifest struct {
+		Workspace  string                 `yaml:"workspace" json:"workspace"`
+		Parts      int                    `yaml:"parts" json:"parts"`
+		Size       int64                  `yaml:"size" json:"size"`
+		Tokens     int                    `yaml:"tokens" json:"tokens"`
+		Chunks     []ManifestChunk        `yaml:"chunks" json:"chunks"`
+		Omitted    []summarize.Omission   `yaml:"omitted" json:"omitted"`
+		Redactions []summarize.Redaction  `yaml:"redactions" json:"redactions"`
+		Truncated  []summarize.Truncation `yaml:"truncated" json:"truncated"`
+		Errors     []summarize.FileError  `yaml:"errors" json:"errors"`
 	}
 
-	// M defines a Message that should be rendered to JSON
-	M struct {
-		Message string `json:"message"`
+	// ManifestChunk is the Path that the Part of a summary split by -chunk was written to with its Files
+	ManifestChunk struct {
+		Path   string           `yaml:"path" json:"path"`
+		Part   int              `yaml:"part" json:"part"`
+		Size   int64            `yaml:"size" json:"size"`
+		Tokens int              `yaml:"tokens" json:"tokens"`
+		Files  []summarize.File `yaml:"files" json:"files"`
 	}
 
-	// seenStrings captures a concurrent safe map of strings and booleans that indicate whether the string has been seen
-	seenStrings struct {
-		mu sync.RWMutex
-		m  map[string]bool
+	// Listed is the -json output of `summarize ls` with every path it reached and the Totals of the included files
+	Listed struct {
+		Paths  []summarize.Listing `yaml:"paths" json:"paths"`
+		Totals ListTotals          `yaml:"totals" json:"totals"`
 	}
 
-	mapData struct {
-		Ext   string
-		Paths []string
+	// ListTotals counts the Included files with their Size and Tokens, and the Excluded files and directories
+	ListTotals struct {
+		Included int   `yaml:"included" json:"included"`
+		Excluded int   `yaml:"excluded" json:"excluded"`
+		Size     int64 `yaml:"size" json:"size"`
+		Tokens   int   `yaml:"tokens" json:"tokens"`
+	}
+
+	// Failure is an error of the summarize program with the Kind that chooses its exit code, the Message that describes
+	// what it was doing and the Err that caused it. A Failure without an Err is printed as its Message alone.
+	Failure struct {
+		Kind    string
+		Message string
+		Err     error
+	}
+
+	// M defines a Message that should be rendered to JSON, with the Type of the error when it reports one
+	M struct {
+		Message string `json:"message"`
+		Type    string `json:"type,omitempty"`
 	}
 )
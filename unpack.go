@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+// unpack runs `summarize unpack <summary> -to <dir>`, which recreates the files of a summary in any format inside of
+// the kTo directory with their modes, leaving the files that already exist and the files that were truncated or redacted
+// alone unless -force is given
+func unpack() error {
+	if err := load(); err != nil {
+		return err
+	}
+	args := flag.Args()
+	if len(args) != 1 {
+		return usage("unpack <summary> [-to <dir>] [-force]")
+	}
+	doc, err := readSummary(args[0])
+	if err != nil {
+		return err
+	}
+	to := *figs.String(kTo)
+	force := *figs.Bool(kForce)
+	written, skipped, altered, err := doc.Unpack(to, force)
+	if err != nil {
+		return fail(summarize.KindRender, "unpacking summary", err)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Unpacked %d files to %s\n", len(written), to))
+	if len(skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("Skipped %d files that already exist, use -force to overwrite them:\n", len(skipped)))
+		for _, path := range skipped {
+			sb.WriteString("  " + path + "\n")
+		}
+	}
+	if len(altered) > 0 && force {
+		sb.WriteString(fmt.Sprintf("Unpacked %d files as they were truncated or redacted in the summary:\n", len(altered)))
+	} else if len(altered) > 0 {
+		sb.WriteString(fmt.Sprintf("Skipped %d files that were truncated or redacted, use -force to unpack them as summarized:\n", len(altered)))
+	}
+	for _, path := range altered {
+		sb.WriteString("  " + path + "\n")
+	}
+	announce(sb.String())
+	return nil
+}
@@ -1,10 +1,10 @@
 package main
 
 import (
-	"sync"
+	"errors"
 
 	"github.com/andreimerlescu/figtree/v2"
-	"github.com/andreimerlescu/sema"
+	"github.com/andreimerlescu/summarize/pkg/summarize"
 )
 
 var (
@@ -14,99 +14,73 @@ var (
 	defaultExclude = []string{
 		"useExpanded",
 	}
-	// defaultExclude are the -exc list of extensions that will be skipped automatically
-	extendedDefaultExclude = []string{
-		// Compressed archives
-		"7z", "gz", "xz", "zst", "zstd", "bz", "bz2", "bzip2", "zip", "tar", "rar", "lz4", "lzma", "cab", "arj",
+	// extendedDefaultExclude are the -x list of extensions that will be skipped automatically
+	extendedDefaultExclude = summarize.DefaultExcludeExt
 
-		// Encryption, certificates, and sensitive keys
-		"crt", "cert", "cer", "key", "pub", "asc", "pem", "p12", "pfx", "jks", "keystore",
-		"id_rsa", "id_dsa", "id_ed25519", "id_ecdsa", "gpg", "pgp",
-
-		// Binary & executable artifacts
-		"exe", "dll", "so", "dylib", "bin", "out", "o", "obj", "a", "lib", "dSYM",
-		"class", "pyc", "pyo", "__pycache__",
-		"jar", "war", "ear", "apk", "ipa", "dex", "odex",
-		"wasm", "node", "beam", "elc",
-
-		// System and disk images
-		"iso", "img", "dmg", "vhd", "vdi", "vmdk", "qcow2",
-
-		// Database files
-		"db", "sqlite", "sqlite3", "db3", "mdb", "accdb", "sdf", "ldb",
-
-		// Log files
-		"log", "trace", "dump", "crash",
-
-		// Media files - Images
-		"jpg", "jpeg", "png", "gif", "bmp", "tiff", "tif", "webp", "ico", "svg", "heic", "heif", "raw", "cr2", "nef", "dng",
-
-		// Media files - Audio
-		"mp3", "wav", "flac", "aac", "ogg", "wma", "m4a", "opus", "aiff",
-
-		// Media files - Video
-		"mp4", "avi", "mov", "mkv", "webm", "flv", "wmv", "m4v", "3gp", "ogv",
-
-		// Font files
-		"ttf", "otf", "woff", "woff2", "eot", "fon", "pfb", "pfm",
-
-		// Document formats (typically not source code)
-		"pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "odt", "ods", "odp", "rtf",
-
-		// IDE/Editor/Tooling artifacts
-		"suo", "sln", "user", "ncb", "pdb", "ipch", "ilk", "tlog", "idb", "aps", "res",
-		"iml", "idea", "vscode", "project", "classpath", "factorypath", "prefs",
-		"vcxproj", "vcproj", "filters", "xcworkspace", "xcuserstate", "xcscheme", "pbxproj",
-		"DS_Store", "Thumbs.db", "desktop.ini",
-
-		// Package manager and build artifacts
-		"lock", "sum", "resolved", // package-lock.json, go.sum, yarn.lock, etc.
-
-		// Temporary and backup files
-		"tmp", "temp", "swp", "swo", "bak", "backup", "orig", "rej", "patch",
-		"~", "old", "new", "part", "incomplete",
+	// defaultInclude are the -i list of extensions that will be included in the summary
+	defaultInclude = []string{
+		"useExpanded",
+	}
 
-		// Source maps and minified files (usually generated)
-		"map", "min.js", "min.css", "bundle.js", "bundle.css", "chunk.js",
+	extendedDefaultInclude = summarize.DefaultIncludeExt
 
-		// Configuration that's typically binary or generated
-		"dat", "data", "cache", "pid", "sock",
+	defaultAvoid = []string{
+		"useExpanded",
+	}
 
-		// Version control artifacts (though usually in ignored directories)
-		"pack", "idx", "rev",
+	// extendedDefaultAvoid are the -s list of substrings in file path names to avoid in the summary
+	extendedDefaultAvoid = summarize.DefaultSkipContains
 
-		// Other binary formats
-		"pickle", "pkl", "npy", "npz", "mat", "rdata", "rds",
+	// commands are run instead of summarizing when the first argument is their name, such as `summarize cache prune`
+	commands = map[string]func() error{
+		"apply":  apply,
+		"cache":  cache,
+		"diff":   diff,
+		"ls":     ls,
+		"unpack": unpack,
 	}
 
-	// defaultInclude are the -inc list of extensions that will be included in the summary
-	defaultInclude = []string{
-		"useExpanded",
+	// chatCommands are the messages of -chat that are handled by model.command instead of being sent to the model
+	chatCommands = map[string]bool{"/apply": true, "/confirm": true, "/cancel": true}
+
+	// exitCodes are the exit codes of the kinds of errors that end the summarize program
+	exitCodes = map[string]int{
+		summarize.KindConfig: exitConfig,
+		summarize.KindWalk:   exitWalk,
+		summarize.KindRead:   exitRead,
+		summarize.KindRender: exitRender,
+		kindAI:               exitAI,
+		kindSecret:           exitSecret,
 	}
 
-	extendedDefaultInclude = []string{
-		"go", "ts", "tf", "sh", "py", "js", "Makefile", "mod", "Dockerfile", "dockerignore", "gitignore", "esconfigs", "md",
+	// ruleFlags are the flags that set the rules of a summarize.Explanation, printed by -explain
+	ruleFlags = map[string]string{
+		summarize.RuleSkip:         "-" + kSkipContains,
+		summarize.RuleDots:         "-" + kDots,
+		summarize.RuleIgnoreFile:   "the ignore file",
+		summarize.RuleExcludeRegex: "-" + kExcludeRegex,
+		summarize.RuleExcludeGlob:  "-" + kExcludeGlob,
+		summarize.RuleIncludeGlob:  "-" + kIncludeGlob,
+		summarize.RuleExcludeExt:   "-" + kExcludeExt,
+		summarize.RuleIncludeExt:   "-" + kIncludeExt,
 	}
 
-	defaultAvoid = []string{
-		"useExpanded",
+	// contentFlags are the flags of the checks that exclude a file by its contents, printed by `summarize ls`
+	contentFlags = map[string]string{
+		summarize.OmitBinary:      "-" + kSkipBinary,
+		summarize.OmitInvalidUTF8: "-" + kSkipInvalidUTF8,
+		summarize.OmitLongLines:   "-" + kMaxLineLength,
+		summarize.OmitGenerated:   "-" + kSkipGenerated,
+		summarize.OmitExcluded:    "its name",
+		summarize.OmitReadError:   "a read error",
 	}
 
-	// extendedDefaultAvoid are the -avoid list of substrings in file path names to avoid in the summary
-	extendedDefaultAvoid = []string{
-		".min.js", ".min.css", ".git/", ".svn/", ".vscode/", ".vs/", ".idea/", "logs/", "secrets/",
-		".venv/", "/site-packages", ".terraform/", "summaries/", "node_modules/", "/tmp", "tmp/", "logs/",
-	}
+	// errDone ends the summarize program successfully once it printed what was asked of it, such as the -version
+	errDone = errors.New("done")
 
-	data                                                   *sync.Map
 	isDebug                                                bool
 	sourceDir                                              string
+	sourceRoots                                            []summarize.Root
 	outputDir                                              string
 	inc, exc, ski, lIncludeExt, lExcludeExt, lSkipContains []string
-	errs                                                   []error
-	toUpdate                                               []mapData
-	wg, writerWG                                           *sync.WaitGroup
-	throttler, maxFileSemaphore                            sema.Semaphore
-	seen                                                   *seenStrings
-	resultsChan                                            chan Result
 )
@@ -2,9 +2,10 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/andreimerlescu/checkfs/directory"
 	"github.com/andreimerlescu/checkfs/file"
 	"github.com/andreimerlescu/figtree/v2"
+	"github.com/andreimerlescu/summarize/pkg/summarize"
 )
 
 // newSummaryFilename returns summary.time.Now().UTC().Format(tFormat).md
@@ -25,8 +27,53 @@ var callbackVerifyFile = func(value interface{}) error {
 }
 
 // callbackVerifyReadableDirectory is a figtree WithCallback on the kSourceDir that uses checkfs.Directory to be More Permissive than 0444
+// for the directory of every root
 var callbackVerifyReadableDirectory = func(value interface{}) error {
-	return check.Directory(toString(value), directory.Options{Exists: true, MorePermissiveThan: 0444})
+	roots := parseRoots(toString(value))
+	if len(roots) == 0 {
+		return errors.New("no source directory")
+	}
+	for _, root := range roots {
+		if err := check.Directory(root.Dir, directory.Options{Exists: true, MorePermissiveThan: 0444}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitList returns the comma separated items of the value in the order they were given, without the empty ones,
+// where a figtree list would sort them
+var splitList = func(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// dotsPolicy returns the summarize.Options Dots policy of the kDots value, which the deprecated kDotFiles replaces with
+// summarize.DotsExclude whenever it is set
+var dotsPolicy = func(dots string, noDotFiles bool) string {
+	if noDotFiles {
+		return summarize.DotsExclude
+	}
+	return dots
+}
+
+// parseRoots returns the summarize.Root of every comma separated directory of the kSourceDir value, which is written
+// as alias=dir to give the root an alias
+var parseRoots = func(value string) []summarize.Root {
+	var roots []summarize.Root
+	for _, item := range splitList(value) {
+		alias, dir, ok := strings.Cut(item, "=")
+		if !ok {
+			alias, dir = "", item
+		}
+		roots = append(roots, summarize.Root{Dir: dir, Alias: alias})
+	}
+	return roots
 }
 
 // toString uses figtree NewFlesh to return the ToString() value of the provided value argument
@@ -43,29 +90,62 @@ var toString = func(value interface{}) string {
 	}
 }
 
-// capture assures that the d errors are not nil then runs terminate to write to os.Stderr
-var capture = func(msg string, d ...error) {
-	if len(d) == 0 || (len(d) == 1 && d[0] == nil) {
-		return
+// fail returns a Failure of the kind with the msg when the err is not nil
+var fail = func(kind, msg string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Failure{Kind: kind, Message: msg, Err: err}
+}
+
+// usage returns the Failure that prints how to run a command with its synopsis, such as "diff <old> <new>"
+var usage = func(synopsis string) error {
+	return &Failure{Kind: summarize.KindConfig, Message: fmt.Sprintf("Usage: %s %s\n", filepath.Base(os.Args[0]), synopsis)}
+}
+
+// kindOf returns the kind of the err from the Failure or summarize.Error it wraps, or an empty string when it has none
+var kindOf = func(err error) string {
+	if errors.Is(err, summarize.ErrSecretFound) {
+		return kindSecret
+	}
+	var failure *Failure
+	if errors.As(err, &failure) && len(failure.Kind) > 0 {
+		return failure.Kind
+	}
+	var summarizeErr *summarize.Error
+	if errors.As(err, &summarizeErr) {
+		return summarizeErr.Kind
 	}
-	terminate(os.Stderr, "[EXCUSE ME, BUT] %s\n\ncaptured error: %v\n", msg, d)
+	return ""
 }
 
-// terminate can write to os.Stderr or os.Stdout with a fmt.Fprintf format as i and a variadic interface of e that gets rendered to d either in plain text or as JSON
-var terminate = func(d io.Writer, i string, e ...interface{}) {
+// report writes the err to os.Stderr, or to os.Stdout as an M with its Type when -json is enabled, and returns the exit
+// code of its kind
+var report = func(err error) int {
+	if err == nil || errors.Is(err, errDone) {
+		return exitOK
+	}
+	kind := kindOf(err)
+	message := err.Error()
+	var failure *Failure
+	if errors.As(err, &failure) && failure.Err != nil {
+		message = fmt.Sprintf("[EXCUSE ME, BUT] %s\n\ncaptured error: %v\n", failure.Message, failure.Err)
+	}
+	code, ok := exitCodes[kind]
+	if !ok {
+		code = exitError
+	}
+	// the figs may not be loaded yet when the err ended the program
 	for _, f := range os.Args {
 		if strings.HasPrefix(f, "-json") {
-			mm := M{Message: fmt.Sprintf(i, e...)}
-			jb, err := json.MarshalIndent(mm, "", "  ")
-			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Error serializing json: %v\n", err)
-				_, _ = fmt.Fprintf(d, i, e...)
-			} else {
+			jb, jsonErr := json.MarshalIndent(M{Message: message, Type: kind}, "", "  ")
+			if jsonErr == nil {
 				fmt.Println(string(jb))
+				return code
 			}
-			os.Exit(1)
+			_, _ = fmt.Fprintf(os.Stderr, "Error serializing json: %v\n", jsonErr)
 		}
 	}
-	_, _ = fmt.Fprintf(d, i, e...)
-	os.Exit(1)
+	_, _ = fmt.Fprint(os.Stderr, message)
+	return code
 }
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/andreimerlescu/summarize/pkg/summarize"
+)
+
+func TestDotsPolicy(t *testing.T) {
+	for _, dots := range []string{summarize.DotsInclude, summarize.DotsExcludeFiles, summarize.DotsExcludeDirs, summarize.DotsExclude} {
+		if got := dotsPolicy(dots, false); got != dots {
+			t.Errorf("dotsPolicy(%q, false) = %q, want the -dots policy", dots, got)
+		}
+		// the deprecated -ndf wins over any -dots policy
+		if got := dotsPolicy(dots, true); got != summarize.DotsExclude {
+			t.Errorf("dotsPolicy(%q, true) = %q, want %q", dots, got, summarize.DotsExclude)
+		}
+	}
+}